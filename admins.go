@@ -0,0 +1,57 @@
+package unifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// An Admin is an administrator of a UniFi Controller.
+type Admin struct {
+	ID      string       `json:"_id"`
+	Email   string       `json:"email"`
+	IsSuper bool         `json:"is_super"`
+	Name    string       `json:"name"`
+	Roles   []*AdminRole `json:"roles"`
+}
+
+// An AdminRole is the role an Admin holds on a single site.
+type AdminRole struct {
+	Role     string `json:"role"`
+	SiteID   string `json:"site_id"`
+	SiteName string `json:"site_name"`
+}
+
+// Self returns the Admin as which the Client is logged in, along with its
+// roles on each site.
+func (c *Client) Self() (*Admin, error) {
+	return getOnePath[Admin](context.Background(), c, "/api/self")
+}
+
+// AllAdmins returns all of the Admins of the UniFi Controller, along with
+// their roles on each site.
+//
+// AllAdmins may only be called by a super administrator.  If the logged in
+// user is not a super administrator, an error wrapping ErrPermissionDenied
+// is returned.
+func (c *Client) AllAdmins() ([]*Admin, error) {
+	var v struct {
+		Admins []*Admin `json:"data"`
+	}
+
+	req, err := c.newRequest(http.MethodGet, "/api/stat/admin", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req, &v); err != nil {
+		if errors.Is(err, ErrPermissionDenied) {
+			return nil, fmt.Errorf("listing all admins requires a super administrator: %w", err)
+		}
+
+		return nil, err
+	}
+
+	return v.Admins, nil
+}
@@ -0,0 +1,116 @@
+package unifi
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientAllAdmins(t *testing.T) {
+	wantAdmin := &Admin{
+		ID:      "abcdef1234567890",
+		Email:   "admin@example.com",
+		IsSuper: true,
+		Name:    "admin",
+		Roles: []*AdminRole{
+			{Role: "admin", SiteID: "0123456789abcdef", SiteName: "default"},
+			{Role: "readonly", SiteID: "fedcba9876543210", SiteName: "branch"},
+		},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		"/api/stat/admin",
+		nil,
+		struct {
+			Admins []*Admin `json:"data"`
+		}{Admins: []*Admin{wantAdmin}},
+	))
+	defer done()
+
+	admins, err := c.AllAdmins()
+	if err != nil {
+		t.Fatalf("unexpected error from Client.AllAdmins: %v", err)
+	}
+
+	if want, got := []*Admin{wantAdmin}, admins; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Admins:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientAllAdminsPermissionDenied(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"api.err.NoPermission"},"data":[]}`))
+	})
+	defer done()
+
+	_, err := c.AllAdmins()
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrPermissionDenied, err)
+	}
+
+	if want, got := "api.err.NoPermission", err.Error(); !strings.Contains(got, want) {
+		t.Fatalf("error does not contain controller message:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientSelf(t *testing.T) {
+	wantAdmin := &Admin{
+		ID:    "abcdef1234567890",
+		Email: "admin@example.com",
+		Name:  "admin",
+	}
+
+	var tests = []struct {
+		desc  string
+		data  interface{}
+		admin *Admin
+		err   error
+	}{
+		{
+			desc:  "array",
+			data:  []*Admin{wantAdmin},
+			admin: wantAdmin,
+		},
+		{
+			desc:  "object",
+			data:  wantAdmin,
+			admin: wantAdmin,
+		},
+		{
+			desc: "empty array",
+			data: []*Admin{},
+			err:  ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				"/api/self",
+				nil,
+				map[string]interface{}{"data": tt.data},
+			))
+			defer done()
+
+			admin, err := c.Self()
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if err != nil {
+				return
+			}
+
+			if want, got := tt.admin, admin; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected Admin:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
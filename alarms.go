@@ -2,28 +2,125 @@ package unifi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net"
+	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
 // Alarms returns all of the Alarms for a specified site name.
 func (c *Client) Alarms(siteName string) ([]*Alarm, error) {
+	alarms, _, err := c.alarms(siteName, nil, nil)
+	return alarms, err
+}
+
+// AlarmsPage returns a page of at most limit Alarms for a specified site name,
+// beginning at offset start, along with the total number of Alarms available
+// across all pages.  The total is 0 if the UniFi Controller did not report
+// one.
+func (c *Client) AlarmsPage(siteName string, start int, limit int) ([]*Alarm, int, error) {
+	return c.alarms(siteName, nil, &pageQuery{
+		Start: start,
+		Limit: limit,
+	})
+}
+
+// An AlarmFilter selects a subset of the Alarms for a site.  The zero value
+// selects the Alarms which have not been archived.
+type AlarmFilter struct {
+	// Start and Limit select a page of at most Limit Alarms, beginning at
+	// offset Start.  If Limit is zero, all matching Alarms are returned.
+	Start int
+	Limit int
+
+	// Archived selects the Alarms which have been archived instead of those
+	// which have not.
+	Archived bool
+
+	// Within selects the Alarms which occurred within the specified
+	// duration from now, rounded up to the nearest hour.  Alternatively,
+	// Begin and End select the Alarms which occurred between two times,
+	// either of which may be zero to leave the range open.  Within may not
+	// be combined with Begin and End.
+	Within time.Duration
+	Begin  time.Time
+	End    time.Time
+}
+
+// AlarmsFilter returns the Alarms for a specified site name which match the
+// filter specified by opts.  If opts is nil, the zero AlarmFilter is used.
+func (c *Client) AlarmsFilter(siteName string, opts *AlarmFilter) ([]*Alarm, error) {
+	if opts == nil {
+		opts = &AlarmFilter{}
+	}
+
+	if opts.Within > 0 && (!opts.Begin.IsZero() || !opts.End.IsZero()) {
+		return nil, errors.New("alarm filter must not specify both Within and Begin or End")
+	}
+
+	q := &alarmQuery{
+		pageQuery: pageQuery{
+			Start: opts.Start,
+			Limit: opts.Limit,
+		},
+	}
+	if opts.Within > 0 {
+		q.Within = int(math.Ceil(opts.Within.Hours()))
+	}
+	if !opts.Begin.IsZero() {
+		q.Begin = unixMilli(opts.Begin)
+	}
+	if !opts.End.IsZero() {
+		q.End = unixMilli(opts.End)
+	}
+
+	params := url.Values{"archived": {strconv.FormatBool(opts.Archived)}}
+
+	alarms, _, err := c.alarms(siteName, params, q)
+	return alarms, err
+}
+
+// An alarmQuery is the raw structure of a query used to filter Alarms.
+type alarmQuery struct {
+	pageQuery
+	Within int   `json:"within,omitempty"`
+	Begin  int64 `json:"start,omitempty"`
+	End    int64 `json:"end,omitempty"`
+}
+
+// alarms retrieves Alarms for a specified site name, using optional query
+// parameters and query body to filter the results.  The total number of
+// Alarms reported by the UniFi Controller is also returned.
+func (c *Client) alarms(siteName string, params url.Values, q interface{}) ([]*Alarm, int, error) {
 	var v struct {
+		Meta   pageMeta `json:"meta"`
 		Alarms []*Alarm `json:"data"`
 	}
 
-	req, err := c.newRequest(
-		"GET",
-		fmt.Sprintf("/api/s/%s/list/alarm", siteName),
-		nil,
-	)
+	method := http.MethodGet
+	if q != nil {
+		method = http.MethodPost
+	}
+
+	endpoint := fmt.Sprintf("/api/s/%s/list/alarm", siteName)
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	req, err := c.newRequest(method, endpoint, q)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	_, err = c.do(req, &v)
-	return v.Alarms, err
+	if _, err := c.do(req, &v); err != nil {
+		return nil, 0, err
+	}
+
+	return v.Alarms, v.Meta.total(), nil
 }
 
 // An Alarm is an alert which is triggered when a Device becomes
@@ -40,6 +137,10 @@ type Alarm struct {
 	Subsystem string
 }
 
+// raw implements rawUnmarshaler, returning the raw structure from which
+// an Alarm is unmarshaled.
+func (*Alarm) raw() interface{} { return new(alarm) }
+
 // UnmarshalJSON unmarshals the raw JSON representation of an Alarm.
 func (a *Alarm) UnmarshalJSON(b []byte) error {
 	var al alarm
@@ -2,6 +2,7 @@ package unifi
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -69,6 +70,146 @@ func TestClientAlarms(t *testing.T) {
 	}
 }
 
+func TestClientAlarmsPage(t *testing.T) {
+	const wantSite = "default"
+
+	v := struct {
+		Meta   pageMeta `json:"meta"`
+		Alarms []alarm  `json:"data"`
+	}{
+		Meta: pageMeta{Count: 42},
+		Alarms: []alarm{{
+			ID:       "abcdef123457890",
+			AP:       "de:ad:be:ef:de:ad",
+			DateTime: "2016-01-01T00:00:00Z",
+		}},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/list/alarm", wantSite),
+		&pageQuery{Start: 41, Limit: 10},
+		v,
+	))
+	defer done()
+
+	alarms, total, err := c.AlarmsPage(wantSite, 41, 10)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.AlarmsPage: %v", err)
+	}
+
+	if want, got := 1, len(alarms); want != got {
+		t.Fatalf("unexpected number of Alarms:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+
+	if want, got := 42, total; want != got {
+		t.Fatalf("unexpected total number of Alarms:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+}
+
+func TestClientAlarmsFilter(t *testing.T) {
+	const wantSite = "default"
+
+	var (
+		begin = time.Date(2016, time.January, 01, 0, 0, 0, 0, time.UTC)
+		end   = begin.Add(24 * time.Hour)
+	)
+
+	var tests = []struct {
+		desc     string
+		opts     *AlarmFilter
+		archived string
+		body     string
+		err      error
+	}{
+		{
+			desc:     "no filter",
+			archived: "false",
+			body:     `{}`,
+		},
+		{
+			desc: "page of archived",
+			opts: &AlarmFilter{
+				Start:    20,
+				Limit:    10,
+				Archived: true,
+			},
+			archived: "true",
+			body:     `{"_start":20,"_limit":10}`,
+		},
+		{
+			desc: "within",
+			opts: &AlarmFilter{
+				Limit:  10,
+				Within: 90 * time.Minute,
+			},
+			archived: "false",
+			body:     `{"_limit":10,"within":2}`,
+		},
+		{
+			desc: "begin and end",
+			opts: &AlarmFilter{
+				Begin: begin,
+				End:   end,
+			},
+			archived: "false",
+			body:     `{"start":1451606400000,"end":1451692800000}`,
+		},
+		{
+			desc: "within and begin",
+			opts: &AlarmFilter{
+				Within: time.Hour,
+				Begin:  begin,
+			},
+			err: errors.New("must not specify both Within and Begin or End"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if want, got := tt.archived, r.URL.Query().Get("archived"); want != got {
+					t.Fatalf("unexpected archived parameter:\n- want: %v\n-  got: %v", want, got)
+				}
+
+				testHandler(
+					t,
+					http.MethodPost,
+					fmt.Sprintf("/api/s/%s/list/alarm", wantSite),
+					json.RawMessage(tt.body),
+					struct {
+						Alarms []alarm `json:"data"`
+					}{Alarms: []alarm{{
+						ID:       "abcdef123457890",
+						AP:       "de:ad:be:ef:de:ad",
+						DateTime: "2016-01-01T00:00:00Z",
+					}}},
+				)(w, r)
+			})
+			defer done()
+
+			alarms, err := c.AlarmsFilter(wantSite, tt.opts)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if tt.err != nil {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error from Client.AlarmsFilter: %v", err)
+			}
+
+			if want, got := 1, len(alarms); want != got {
+				t.Fatalf("unexpected number of Alarms:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+		})
+	}
+}
+
 func TestAlarmUnmarshalJSON(t *testing.T) {
 	var tests = []struct {
 		desc string
@@ -4,16 +4,28 @@ package unifi
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"mime"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
+// Version is the version of this package, which is reported to the UniFi
+// Controller in the default User-Agent header.
+const Version = "0.1.0"
+
 const (
 	// Predefined content types for HTTP requests.
 	formEncodedContentType = "application/x-www-form-urlencoded"
@@ -21,9 +33,38 @@ const (
 
 	// userAgent is the default user agent this package will report to the UniFi
 	// Controller v4 API.
-	userAgent = "github.com/mdlayher/unifi"
+	userAgent = "mdlayher/unifi/" + Version
+
+	// defaultPollInterval is the default interval used by methods which
+	// poll the UniFi Controller while waiting for a change.
+	defaultPollInterval = 5 * time.Second
 )
 
+// ErrLoginRequired is returned when the UniFi Controller responds to a request
+// by redirecting to a login page, indicating that Client.Login must be called
+// before any additional actions can be performed.
+var ErrLoginRequired = errors.New("login required")
+
+// ErrNotFound is returned when a requested item does not exist on the UniFi
+// Controller.
+var ErrNotFound = errors.New("not found")
+
+// ErrClosed is returned when an action is performed with a Client after
+// Client.Close has been called.
+var ErrClosed = errors.New("client closed")
+
+// ErrPermissionDenied is returned when the logged in user does not have
+// permission to perform an action.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// ErrTimeout is returned when an operation does not complete before its
+// timeout elapses.
+var ErrTimeout = errors.New("timed out")
+
+// ErrUnsupported is returned when the UniFi Controller does not support an
+// action, such as a command which is not offered by its version.
+var ErrUnsupported = errors.New("not supported by controller")
+
 // InsecureHTTPClient creates a *http.Client which does not verify a UniFi
 // Controller's certificate chain and hostname.
 //
@@ -45,10 +86,183 @@ func InsecureHTTPClient(timeout time.Duration) *http.Client {
 // Client.Login must be called and return a nil error before any additional
 // actions can be performed with a Client.
 type Client struct {
+	// UserAgent is sent in the User-Agent header of every request, so that
+	// the UniFi Controller's access logs identify the application.  If
+	// empty, a default which includes Version is used.
 	UserAgent string
 
-	apiURL *url.URL
-	client *http.Client
+	// StrictJSON, if true, causes responses from the UniFi Controller to be
+	// rejected if they contain fields which are not known to this package.
+	// This is useful in tests to detect changes to the UniFi Controller API,
+	// but should not be used in production.
+	StrictJSON bool
+
+	// RateLimit, if greater than zero, limits the number of requests per
+	// second the Client will issue to the UniFi Controller.  Requests which
+	// exceed the limit block until they may proceed or their context is
+	// canceled.
+	RateLimit float64
+
+	// PollError, if not nil, is called with any error which causes
+	// Client.Poll, Client.WatchDevices, or Client.PresenceStream to skip a
+	// polling cycle.
+	PollError func(err error)
+
+	// UniFiOS indicates that the UniFi Controller runs on a UniFi OS
+	// console, such as a UniFi Dream Machine.  When set, the Client
+	// authenticates using the console's login endpoint, prefixes UniFi
+	// Controller API requests with /proxy/network, and enables operations
+	// on the console itself.
+	//
+	// Client.Login sets UniFiOS automatically if it detects a UniFi OS
+	// console.  UniFiOS must not be modified directly once the Client is in
+	// use.
+	UniFiOS bool
+
+	// AllowReboot must be set to permit Client.RebootController, which
+	// reboots the entire UniFi OS console.
+	AllowReboot bool
+
+	apiURL       *url.URL
+	client       *http.Client
+	limiter      limiter
+	pollInterval time.Duration
+
+	// retryAttempts and retryDelay configure retries of transient failures,
+	// as set by WithRetry.
+	retryAttempts int
+	retryDelay    time.Duration
+
+	// relogin contains the credentials used to log in again when the
+	// session expires, as set by WithAutoRelogin.  reloginMu serializes
+	// those logins, so that concurrent requests which observe the same
+	// expired session log in only once.
+	relogin   *login
+	reloginMu sync.Mutex
+
+	// loggedIn reports whether Login has succeeded, session counts the
+	// number of successful logins, and csrfToken is the most recent CSRF
+	// token issued by a UniFi OS console, which must accompany requests that
+	// modify state.  mu also guards the UniFiOS field, which Login may set.
+	mu        sync.Mutex
+	loggedIn  bool
+	session   uint64
+	csrfToken string
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// An Option configures a Client created by New.
+type Option func(o *options)
+
+// options contains the configuration applied by Options.
+type options struct {
+	client    *http.Client
+	timeout   time.Duration
+	userAgent string
+	unifiOS   bool
+
+	retryAttempts int
+	retryDelay    time.Duration
+
+	relogin *login
+}
+
+// WithHTTPClient configures a Client to use the specified HTTP client.  If
+// working with a self-hosted UniFi Controller which does not have a valid TLS
+// certificate, InsecureHTTPClient can be used.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) { o.client = client }
+}
+
+// WithTimeout configures the timeout for each HTTP request made by a Client.
+// If an HTTP client is also specified using WithHTTPClient, a copy of it is
+// made with the timeout applied, so the original is not modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithUserAgent configures the User-Agent header sent with each request made
+// by a Client.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) { o.userAgent = userAgent }
+}
+
+// WithUniFiOS configures whether a Client communicates with a UniFi OS
+// console.  See the UniFiOS field of Client for details.
+func WithUniFiOS(unifiOS bool) Option {
+	return func(o *options) { o.unifiOS = unifiOS }
+}
+
+// WithRetry configures a Client to retry requests which fail transiently, up
+// to a total of maxAttempts attempts.  The Client waits for baseDelay before
+// the first retry, doubling the wait for each subsequent retry, with a random
+// jitter.
+//
+// Only GET requests are retried, as they do not modify the UniFi Controller's
+// state.  They are retried if they fail with a network error, such as when
+// the UniFi Controller is restarting, or with HTTP status 502, 503, or 504.
+// Retries stop if the request's context is canceled.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(o *options) {
+		o.retryAttempts = maxAttempts
+		o.retryDelay = baseDelay
+	}
+}
+
+// WithAutoRelogin configures a Client to log in again using the specified
+// username and password when its session with the UniFi Controller expires.
+// The request which found the session to be expired is then sent again, once.
+//
+// Client.Login must still be called to establish the initial session.
+func WithAutoRelogin(username string, password string) Option {
+	return func(o *options) {
+		o.relogin = &login{
+			Username: username,
+			Password: password,
+		}
+	}
+}
+
+// New creates a new Client for the UniFi Controller at the specified base URL,
+// configured using opts.  If no HTTP client is specified using WithHTTPClient,
+// a default one will be used.
+//
+// Client.Login must be called and return a nil error before any additional
+// actions can be performed with a Client.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	var o options
+	for _, fn := range opts {
+		fn(&o)
+	}
+
+	client := o.client
+	if o.timeout > 0 {
+		if client == nil {
+			client = &http.Client{}
+		} else {
+			cc := *client
+			client = &cc
+		}
+
+		client.Timeout = o.timeout
+	}
+
+	c, err := NewClient(baseURL, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.userAgent != "" {
+		c.UserAgent = o.userAgent
+	}
+	c.UniFiOS = o.unifiOS
+	c.retryAttempts = o.retryAttempts
+	c.retryDelay = o.retryDelay
+	c.relogin = o.relogin
+
+	return c, nil
 }
 
 // NewClient creates a new Client, using the input API address and an optional
@@ -59,6 +273,8 @@ type Client struct {
 //
 // Client.Login must be called and return a nil error before any additional
 // actions can be performed with a Client.
+//
+// Deprecated: use New with WithHTTPClient.
 func NewClient(addr string, client *http.Client) (*Client, error) {
 	// Trim trailing slash to ensure sane path creation in other methods
 	u, err := url.Parse(strings.TrimRight(addr, "/"))
@@ -83,8 +299,10 @@ func NewClient(addr string, client *http.Client) (*Client, error) {
 	c := &Client{
 		UserAgent: userAgent,
 
-		apiURL: u,
-		client: client,
+		apiURL:       u,
+		client:       client,
+		pollInterval: defaultPollInterval,
+		closed:       make(chan struct{}),
 	}
 
 	return c, nil
@@ -93,21 +311,132 @@ func NewClient(addr string, client *http.Client) (*Client, error) {
 // Login authenticates against the UniFi Controller using the specified
 // username and password.  Login must be called and return a nil error before
 // any additional actions can be performed.
+//
+// If the Client's UniFiOS field is not set but the UniFi Controller is found
+// to run on a UniFi OS console, Login sets UniFiOS and authenticates with the
+// console instead.
 func (c *Client) Login(username string, password string) error {
 	auth := &login{
 		Username: username,
 		Password: password,
 	}
 
-	req, err := c.newRequest(http.MethodPost, "/api/login", auth)
+	err := c.login(auth)
+
+	// UniFi OS consoles do not serve the classic login endpoint, so switch
+	// to UniFi OS mode and try again.
+	var perr *PrefixError
+	if !c.isUniFiOS() && errors.As(err, &perr) {
+		c.mu.Lock()
+		c.UniFiOS = true
+		c.mu.Unlock()
+
+		err = c.login(auth)
+	}
+
+	return err
+}
+
+// login performs a single login attempt using the endpoint appropriate for
+// the type of UniFi Controller.
+func (c *Client) login(auth *login) error {
+	endpoint := "/api/login"
+	if c.isUniFiOS() {
+		endpoint = "/api/auth/login"
+	}
+
+	req, err := c.newRequest(http.MethodPost, endpoint, auth)
+	if err != nil {
+		return err
+	}
+
+	// Never attempt to log in again in response to a failed login.
+	if _, err := c.doOnce(req, nil); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loggedIn = true
+	c.session++
+
+	return nil
+}
+
+// Logout ends the Client's session with the UniFi Controller.  Login must be
+// called again before any additional actions can be performed.
+//
+// Once the UniFi Controller has been asked to invalidate the session, the
+// Client expires its cookies for the UniFi Controller, even if the request
+// failed.  Cookies the HTTP client's jar holds for other hosts are kept.  If
+// Login has not succeeded since the last call to Logout and the jar holds no
+// cookies for the UniFi Controller, an error wrapping ErrLoginRequired is
+// returned.
+func (c *Client) Logout() error {
+	c.mu.Lock()
+	loggedIn := c.loggedIn
+	c.mu.Unlock()
+
+	// A session may also have been restored from an existing cookie jar.
+	if !loggedIn && len(c.client.Jar.Cookies(c.apiURL)) == 0 {
+		return fmt.Errorf("cannot log out before a successful Client.Login: %w", ErrLoginRequired)
+	}
+
+	endpoint := "/api/logout"
+	if c.isUniFiOS() {
+		endpoint = "/api/auth/logout"
+	}
+
+	req, err := c.newRequest(http.MethodPost, endpoint, nil)
 	if err != nil {
 		return err
 	}
 
 	_, err = c.do(req, nil)
+
+	// The jar may belong to the caller's HTTP client, so expire only the
+	// UniFi Controller's cookies rather than replacing the jar.
+	cookies := c.client.Jar.Cookies(c.apiURL)
+	for _, ck := range cookies {
+		ck.Path = "/"
+		ck.Value = ""
+		ck.MaxAge = -1
+	}
+	c.client.Jar.SetCookies(c.apiURL, cookies)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loggedIn = false
+	c.csrfToken = ""
+
 	return err
 }
 
+// Close stops any goroutines started by the Client, such as those which
+// deliver a PresenceStream, and closes any idle HTTP connections.  After
+// Close is called, all actions performed with the Client return ErrClosed.
+//
+// Close does not end the Client's session with the UniFi Controller; call
+// Logout before Close to do so.  Close is safe to call more than once.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.client.CloseIdleConnections()
+	})
+
+	return nil
+}
+
+// isClosed reports whether Close has been called.
+func (c *Client) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
 type login struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -115,18 +444,23 @@ type login struct {
 
 // newRequest creates a new HTTP request, using the specified HTTP method and
 // API endpoint. Additionally, it accepts a struct which can be marshaled to
-// a JSON body.
+// a JSON body.  If the Client's UniFiOS field is set, the endpoint is
+// rewritten for a UniFi OS console.
 func (c *Client) newRequest(method string, endpoint string, body interface{}) (*http.Request, error) {
+	if c.isUniFiOS() {
+		endpoint = uniFiOSPath(endpoint)
+	}
+
 	rel, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, err
 	}
 	u := c.apiURL.ResolveReference(rel)
 
-	hasBody := method == http.MethodPost && body != nil
+	hasBody := (method == http.MethodPost || method == http.MethodPut) && body != nil
 	var length int64
 
-	// If performing a POST request and body parameters exist, encode
+	// If performing a POST or PUT request and body parameters exist, encode
 	// them now
 	buf := bytes.NewBuffer(nil)
 	if hasBody {
@@ -141,42 +475,621 @@ func (c *Client) newRequest(method string, endpoint string, body interface{}) (*
 		return nil, err
 	}
 
-	// For POST requests, add proper headers
+	// For POST and PUT requests, add proper headers
 	if hasBody {
 		req.Header.Add("Content-Type", formEncodedContentType)
 		req.ContentLength = length
 	}
 
+	ua := c.UserAgent
+	if ua == "" {
+		ua = userAgent
+	}
+
 	req.Header.Add("Accept", jsonContentType)
-	req.Header.Add("User-Agent", c.UserAgent)
+	req.Header.Set("User-Agent", ua)
 
 	return req, nil
 }
 
+// uniFiOSPath returns the path of a UniFi Controller API endpoint on a UniFi
+// OS console, which serves the UniFi Controller API beneath /proxy/network.
+// The console's own endpoints, such as those used to authenticate, are not
+// rewritten.
+func uniFiOSPath(endpoint string) string {
+	switch {
+	case strings.HasPrefix(endpoint, "/api/auth/"),
+		strings.HasPrefix(endpoint+"/", "/api/system/"):
+		return endpoint
+	case strings.HasPrefix(endpoint, "/api/"), endpoint == "/status":
+		return "/proxy/network" + endpoint
+	default:
+		return endpoint
+	}
+}
+
+// raw performs an HTTP GET request against the specified API endpoint and
+// returns the raw JSON data from the response.
+func (c *Client) raw(endpoint string) (json.RawMessage, error) {
+	var v struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	req, err := c.newRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.do(req, &v)
+	return v.Data, err
+}
+
+// getList performs an HTTP GET request against the specified endpoint for a
+// site, such as "stat/device", and returns the list of values in the data
+// field of the response.
+func getList[T any](ctx context.Context, c *Client, siteName string, endpoint string) ([]*T, error) {
+	return queryList[T](ctx, c, siteName, endpoint, nil)
+}
+
+// queryList is like getList, but if q is not nil, it is sent as the body of
+// an HTTP POST request to select the values returned, such as a page of
+// results.
+func queryList[T any](ctx context.Context, c *Client, siteName string, endpoint string, q interface{}) ([]*T, error) {
+	var v struct {
+		Data []*T `json:"data"`
+	}
+
+	method := http.MethodGet
+	if q != nil {
+		method = http.MethodPost
+	}
+
+	req, err := c.newRequest(
+		method,
+		fmt.Sprintf("/api/s/%s/%s", siteName, endpoint),
+		q,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req.WithContext(ctx), &v); err != nil {
+		return nil, err
+	}
+
+	return v.Data, nil
+}
+
+// getOne performs an HTTP GET request against the specified endpoint for a
+// site, and returns the single value in the data field of the response.  If
+// the response contains no value, ErrNotFound is returned.
+func getOne[T any](ctx context.Context, c *Client, siteName string, endpoint string) (*T, error) {
+	return getOnePath[T](ctx, c, fmt.Sprintf("/api/s/%s/%s", siteName, endpoint))
+}
+
+// getOnePath is like getOne, but for an API endpoint which is not specific to
+// a site, such as "/api/self".
+func getOnePath[T any](ctx context.Context, c *Client, endpoint string) (*T, error) {
+	out := new(T)
+	v := struct {
+		Data singleValue `json:"data"`
+	}{
+		Data: singleValue{v: out},
+	}
+
+	req, err := c.newRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req.WithContext(ctx), &v); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// A singleValue is the data field of a response from an endpoint which
+// returns a single value.  Such endpoints normally wrap the value in a
+// single-element array, but some UniFi Controller versions return the bare
+// object instead, so both forms are unmarshaled into v.  An empty array
+// results in ErrNotFound.
+type singleValue struct {
+	v interface{}
+}
+
+// UnmarshalJSON unmarshals a single value from a JSON array or object.
+func (s singleValue) UnmarshalJSON(b []byte) error {
+	return s.unmarshal(b, false)
+}
+
+// unmarshal unmarshals a single value from a JSON array or object.  If strict
+// is true, fields which are not known to the value are rejected.
+func (s singleValue) unmarshal(b []byte, strict bool) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		var elems []json.RawMessage
+		if err := json.Unmarshal(b, &elems); err != nil {
+			return err
+		}
+
+		if len(elems) == 0 {
+			return ErrNotFound
+		}
+
+		b = elems[0]
+	}
+
+	return unmarshal(b, s.v, strict)
+}
+
+// A pageMeta is the meta block of a response from an endpoint which returns
+// paginated results.  Depending on the endpoint and UniFi Controller version,
+// the total number of results is reported in count, total, or total_count.
+// The rc and msg fields are checked by do.
+type pageMeta struct {
+	Count      int `json:"count"`
+	Total      int `json:"total"`
+	TotalCount int `json:"total_count"`
+}
+
+// total returns the total number of results reported by a pageMeta, or 0 if
+// the UniFi Controller did not report a total.  total_count is preferred,
+// followed by total, as count may hold only the size of the current page.
+func (m pageMeta) total() int {
+	switch {
+	case m.TotalCount > 0:
+		return m.TotalCount
+	case m.Total > 0:
+		return m.Total
+	default:
+		return m.Count
+	}
+}
+
+// A pageQuery is the raw structure of a query used to select a page of
+// results from an endpoint which returns paginated results.
+type pageQuery struct {
+	Start int `json:"_start,omitempty"`
+	Limit int `json:"_limit,omitempty"`
+}
+
 // do performs an HTTP request using req and unmarshals the result onto v, if
-// v is not nil.
+// v is not nil.  If the Client is configured to log in again when its session
+// expires, do does so at most once and sends the request again.
 func (c *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
-	res, err := c.client.Do(req)
+	session := c.sessionID()
+	res, err := c.doOnce(req, v)
+	if c.relogin == nil || !errors.Is(err, ErrLoginRequired) {
+		return res, err
+	}
+
+	// The request body was consumed by the first attempt, so a request with
+	// a body can only be sent again if the body can be recreated.
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return res, err
+		}
+
+		body, berr := req.GetBody()
+		if berr != nil {
+			return res, err
+		}
+		retry.Body = body
+	}
+
+	if lerr := c.reloginOnce(session); lerr != nil {
+		return res, fmt.Errorf("failed to log in again after session expired: %w", lerr)
+	}
+
+	return c.doOnce(retry, v)
+}
+
+// reloginOnce logs in again using the Client's stored credentials, unless
+// the session has changed since session was observed, in which case another
+// request has already logged in again.
+func (c *Client) reloginOnce(session uint64) error {
+	c.reloginMu.Lock()
+	defer c.reloginMu.Unlock()
+
+	if c.sessionID() != session {
+		return nil
+	}
+
+	return c.Login(c.relogin.Username, c.relogin.Password)
+}
+
+// doOnce performs a single HTTP request using req and unmarshals the result
+// onto v, if v is not nil.
+func (c *Client) doOnce(req *http.Request, v interface{}) (*http.Response, error) {
+	if c.isClosed() {
+		return nil, ErrClosed
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		if token := c.csrf(); token != "" {
+			req.Header.Set("X-CSRF-Token", token)
+		}
+	}
+
+	res, err := c.roundTrip(req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
+	c.updateCSRF(res)
+
 	if err := checkResponse(res); err != nil {
 		return res, err
 	}
 
-	// If no second parameter was passed, do not attempt to handle response
-	if v == nil {
-		return res, nil
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return res, err
+	}
+
+	// Decode the meta block along with v, and report a failure in the meta
+	// block in preference to any error decoding v.
+	meta, err := decodeResponse(b, v, c.StrictJSON)
+	if merr := meta.err(res.StatusCode); merr != nil {
+		return res, merr
+	}
+
+	return res, err
+}
+
+// roundTrip sends req, subject to the Client's rate limit, and retries it if
+// it fails transiently and the Client is configured to retry.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if req.Method == http.MethodGet && c.retryAttempts > 1 {
+		attempts = c.retryAttempts
+	}
+
+	delay := c.retryDelay
+	for i := 1; ; i++ {
+		if c.RateLimit > 0 {
+			if err := c.limiter.wait(req.Context(), c.RateLimit); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := c.client.Do(req)
+		if i >= attempts || !isTransient(req, res, err) {
+			return res, err
+		}
+
+		if res != nil {
+			_, _ = io.Copy(ioutil.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+
+		t := time.NewTimer(jitter(delay))
+		select {
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		case <-t.C:
+		}
+
+		delay *= 2
+	}
+}
+
+// isTransient reports whether the result of a request indicates a failure
+// which may succeed if the request is retried.
+func isTransient(req *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		// Errors caused by the request's context will not go away.
+		return req.Context().Err() == nil
+	}
+
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// A responseMeta is the meta block of a response from the UniFi Controller.
+type responseMeta struct {
+	RC  string `json:"rc"`
+	Msg string `json:"msg"`
+}
+
+// err returns an *APIError if m reports a failure for a response with the
+// specified HTTP status code.  m may be nil if the response had no meta
+// block.
+func (m *responseMeta) err(statusCode int) error {
+	if m == nil {
+		return nil
+	}
+
+	if rc := m.RC; rc != "" && rc != "ok" {
+		return &APIError{
+			StatusCode: statusCode,
+			Code:       rc,
+			Message:    m.Msg,
+		}
+	}
+
+	return nil
+}
+
+// isUniFiOS reports whether the Client communicates with a UniFi OS console.
+func (c *Client) isUniFiOS() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.UniFiOS
+}
+
+// sessionID returns the number of successful logins, which identifies the
+// current session.
+func (c *Client) sessionID() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// csrf returns the current CSRF token issued by a UniFi OS console, if any.
+func (c *Client) csrf() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.csrfToken
+}
+
+// updateCSRF stores the CSRF token from a response, if present.  UniFi OS
+// consoles issue a token on login, and may replace it in later responses.
+func (c *Client) updateCSRF(res *http.Response) {
+	token := res.Header.Get("X-Updated-CSRF-Token")
+	if token == "" {
+		token = res.Header.Get("X-CSRF-Token")
+	}
+	if token == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.csrfToken = token
+}
+
+// A rawUnmarshaler is a type which unmarshals itself from JSON by way of an
+// intermediate raw structure.  raw returns a new value of that structure, so
+// that strict mode can check a response against the fields the UniFi
+// Controller is known to send.
+type rawUnmarshaler interface {
+	raw() interface{}
+}
+
+// decodeResponse decodes a JSON response body b onto v in a single pass, and
+// returns the response's meta block, or nil if it has none.  Each member of
+// a JSON object is decoded into the field of the struct pointed to by v with
+// the same JSON name, matched as encoding/json would, and other members are
+// ignored.  v may be nil, in which
+// case only the meta block is decoded.
+//
+// If strict is true, members and fields which are not known to v are
+// rejected, other than the meta block which every response carries.
+func decodeResponse(b []byte, v interface{}, strict bool) (*responseMeta, error) {
+	fields := jsonFields(v)
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') || (v != nil && fields == nil) {
+		// Bodies which are not JSON objects have no meta block.
+		if v == nil {
+			return nil, nil
+		}
+
+		return nil, unmarshal(b, v, strict)
+	}
+
+	var (
+		meta *responseMeta
+		derr error
+	)
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return meta, err
+		}
+		key, _ := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return meta, err
+		}
+
+		isMeta := strings.EqualFold(key, "meta")
+		if isMeta {
+			// A malformed meta block is treated as absent.
+			meta = new(responseMeta)
+			if err := json.Unmarshal(raw, meta); err != nil {
+				meta = nil
+			}
+		}
+
+		f, ok := lookupField(fields, key)
+		switch {
+		case ok:
+			err = unmarshal(raw, f, strict)
+		case strict && v != nil && !isMeta:
+			err = fmt.Errorf("json: unknown field %q", key)
+		}
+		if err != nil && derr == nil {
+			derr = err
+		}
+	}
+
+	return meta, derr
+}
+
+// unmarshal unmarshals b into v.  If strict is true, fields which are not
+// known to v are rejected.
+func unmarshal(b []byte, v interface{}, strict bool) error {
+	if !strict {
+		return json.Unmarshal(b, v)
+	}
+
+	// A singleValue would otherwise unmarshal its value leniently.
+	if sv, ok := v.(*singleValue); ok {
+		return sv.unmarshal(b, strict)
+	}
+
+	if err := decodeStrict(b, v); err != nil {
+		return err
+	}
+
+	// Types which implement json.Unmarshaler are not subject to the
+	// decoder's checks, so check such a type, or each element of a slice of
+	// such types, against its raw structure directly.
+	if ru, ok := v.(rawUnmarshaler); ok {
+		return decodeStrict(b, ru.raw())
+	}
+
+	ru, ok := sliceElem(v).(rawUnmarshaler)
+	if !ok {
+		return nil
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(b, &elems); err != nil {
+		return err
+	}
+
+	for _, e := range elems {
+		if err := decodeStrict(e, ru.raw()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeStrict unmarshals b into v, rejecting fields which are not known to
+// v.
+func decodeStrict(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// sliceElem returns a new value of the element type of the slice of pointers
+// pointed to by v, or nil if v is not a pointer to such a slice.
+func sliceElem(v interface{}) interface{} {
+	rt := reflect.TypeOf(v)
+	if rt == nil || rt.Kind() != reflect.Ptr || rt.Elem().Kind() != reflect.Slice {
+		return nil
+	}
+
+	et := rt.Elem().Elem()
+	if et.Kind() != reflect.Ptr {
+		return nil
 	}
 
-	return res, json.NewDecoder(res.Body).Decode(v)
+	return reflect.New(et.Elem()).Interface()
+}
+
+// A jsonField is a pointer to a field of a struct, and the field's JSON name.
+type jsonField struct {
+	name string
+	ptr  interface{}
+}
+
+// jsonFields returns pointers to the fields of the struct pointed to by v,
+// along with their JSON names, or nil if v is not a pointer to a struct.  As
+// with encoding/json, the fields of embedded structs are promoted unless a
+// shallower field has the same name.
+func jsonFields(v interface{}) []jsonField {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make([]jsonField, 0)
+	seen := make(map[string]bool)
+
+	embedded := []reflect.Value{rv.Elem()}
+	for len(embedded) > 0 {
+		var next []reflect.Value
+		for _, sv := range embedded {
+			st := sv.Type()
+			for i := 0; i < st.NumField(); i++ {
+				f := st.Field(i)
+				name := strings.Split(f.Tag.Get("json"), ",")[0]
+				if name == "-" {
+					continue
+				}
+
+				if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+					next = append(next, sv.Field(i))
+					continue
+				}
+				if !f.IsExported() {
+					continue
+				}
+
+				if name == "" {
+					name = f.Name
+				}
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+
+				fields = append(fields, jsonField{
+					name: name,
+					ptr:  sv.Field(i).Addr().Interface(),
+				})
+			}
+		}
+
+		embedded = next
+	}
+
+	return fields
+}
+
+// lookupField returns a pointer to the field with the JSON name key.  As with
+// encoding/json, an exact match is preferred, but names are otherwise matched
+// case-insensitively.
+func lookupField(fields []jsonField, key string) (interface{}, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f.ptr, true
+		}
+	}
+
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f.ptr, true
+		}
+	}
+
+	return nil, false
 }
 
 // checkResponse checks for correct content type in a response and for non-200
 // HTTP status codes, and returns any errors encountered.
 func checkResponse(res *http.Response) error {
+	if isUniFiOSNotFound(res) {
+		return &PrefixError{Path: res.Request.URL.Path}
+	}
+
+	// Server errors, such as a reverse proxy's error page while the UniFi
+	// Controller restarts, are reported as such regardless of content type.
+	if res.StatusCode >= 500 {
+		return newAPIError(res)
+	}
+
+	if isLoginPage(res) {
+		return ErrLoginRequired
+	}
+
 	if cType := res.Header.Get("Content-Type"); cType != jsonContentType {
 		return fmt.Errorf("expected %q content type, but received %q", jsonContentType, cType)
 	}
@@ -186,5 +1099,131 @@ func checkResponse(res *http.Response) error {
 		return nil
 	}
 
-	return fmt.Errorf("unexpected HTTP status code: %d", res.StatusCode)
+	return newAPIError(res)
+}
+
+// newAPIError creates an *APIError for a response with a non-2xx HTTP status
+// code, including the result code and message from the response's meta
+// block, if one is present.
+func newAPIError(res *http.Response) *APIError {
+	var v struct {
+		Meta responseMeta `json:"meta"`
+	}
+	_ = json.NewDecoder(res.Body).Decode(&v)
+
+	return &APIError{
+		StatusCode: res.StatusCode,
+		Code:       v.Meta.RC,
+		Message:    v.Meta.Msg,
+	}
+}
+
+// An APIError is returned when the UniFi Controller responds with a non-2xx
+// HTTP status code, or reports a failure in the meta block of a response.
+//
+// An APIError for an expired or missing session matches ErrLoginRequired
+// using errors.Is, and one for an action the logged in user may not perform
+// matches ErrPermissionDenied.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Code is the result code from the meta block, such as "error".  It may
+	// be empty if the response had no meta block.
+	Code string
+
+	// Message is the message from the meta block, such as
+	// "api.err.LoginRequired".  It may be empty.
+	Message string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	if c := e.StatusCode; c != 0 && (c < 200 || c > 299) {
+		if e.Message == "" {
+			return fmt.Sprintf("unexpected HTTP status code: %d", c)
+		}
+
+		return fmt.Sprintf("unexpected HTTP status code: %d: %s", c, e.Message)
+	}
+
+	if e.Message == "" {
+		return fmt.Sprintf("UniFi Controller API error: %s", e.Code)
+	}
+
+	return fmt.Sprintf("UniFi Controller API error: %s: %s", e.Code, e.Message)
+}
+
+// Is reports whether e matches one of this package's sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrLoginRequired:
+		return e.StatusCode == http.StatusUnauthorized || e.Message == "api.err.LoginRequired"
+	case ErrPermissionDenied:
+		return e.StatusCode == http.StatusForbidden || e.Message == "api.err.NoPermission"
+	default:
+		return false
+	}
+}
+
+// isUnsupported reports whether err indicates that the UniFi Controller
+// rejected a command because it does not recognize it.
+func isUnsupported(err error) bool {
+	var aerr *APIError
+	return errors.As(err, &aerr) && aerr.Message == "api.err.UnknownCommand"
+}
+
+// A PrefixError is returned when a UniFi OS console responds to a request for
+// a classic UniFi Controller API endpoint with HTTP 404.  UniFi OS consoles
+// serve the UniFi Network API beneath the /proxy/network prefix, and other
+// applications hosted on the console may handle requests without it.
+type PrefixError struct {
+	// Path is the path of the request which could not be found.
+	Path string
+}
+
+// Error implements error.
+func (e *PrefixError) Error() string {
+	return fmt.Sprintf("UniFi OS console returned HTTP 404 for %q: requests to the UniFi Network application must use the /proxy/network prefix, which is enabled by Client.UniFiOS",
+		e.Path)
+}
+
+// isUniFiOSNotFound determines if a response is an HTTP 404 for a classic API
+// endpoint from a UniFi OS console, which always provides a CSRF token in its
+// responses.
+func isUniFiOSNotFound(res *http.Response) bool {
+	return res.StatusCode == http.StatusNotFound &&
+		res.Header.Get("X-CSRF-Token") != "" &&
+		res.Request != nil &&
+		strings.HasPrefix(res.Request.URL.Path, "/api/")
+}
+
+// isLoginPage determines if a response is a login page rather than an API
+// response.  UniFi OS consoles redirect unauthenticated API requests to an
+// HTML login page instead of returning an error, and some reverse proxies
+// respond with an HTML page and HTTP 401 or 403.  Other HTML responses, such
+// as error pages, are not login pages.
+func isLoginPage(res *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		return false
+	}
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return mediaType == "text/html"
+	}
+
+	// A redirect which leads anywhere other than another API response is
+	// treated as a login page.
+	return res.Request != nil && res.Request.Response != nil
+}
+
+// jitter returns d plus a random duration of up to one fifth of d.
+func jitter(d time.Duration) time.Duration {
+	if d < 5 {
+		return d
+	}
+
+	return d + time.Duration(rand.Int63n(int64(d/5)))
 }
@@ -1,13 +1,18 @@
 package unifi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -49,6 +54,288 @@ func TestClientBadHTTPStatusCode(t *testing.T) {
 	}
 }
 
+func TestClientLoginRequired(t *testing.T) {
+	var tests = []struct {
+		desc string
+		fn   http.HandlerFunc
+	}{
+		{
+			desc: "HTML unauthorized",
+			fn: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`<html></html>`))
+			},
+		},
+		{
+			desc: "HTML forbidden",
+			fn: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`<html></html>`))
+			},
+		},
+		{
+			desc: "redirect",
+			fn: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/login" {
+					http.Redirect(w, r, "/login", http.StatusFound)
+					return
+				}
+
+				_, _ = w.Write([]byte(`login`))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, tt.fn)
+			defer done()
+
+			req, err := c.newRequest(http.MethodGet, "/api/s/default/stat/device", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = c.do(req, nil)
+			if want, got := ErrLoginRequired, err; want != got {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientHTMLNotLoginPage(t *testing.T) {
+	var tests = []struct {
+		desc   string
+		status int
+		err    error
+	}{
+		{
+			desc:   "OK",
+			status: http.StatusOK,
+			err:    errors.New(`received "text/html; charset=utf-8"`),
+		},
+		{
+			desc:   "bad gateway",
+			status: http.StatusBadGateway,
+			err:    errors.New("unexpected HTTP status code: 502"),
+		},
+		{
+			desc:   "service unavailable after redirect",
+			status: http.StatusServiceUnavailable,
+			err:    errors.New("unexpected HTTP status code: 503"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.status == http.StatusServiceUnavailable && r.URL.Path != "/error" {
+					http.Redirect(w, r, "/error", http.StatusFound)
+					return
+				}
+
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`<html></html>`))
+			})
+			defer done()
+
+			req, err := c.newRequest(http.MethodGet, "/api/s/default/stat/device", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = c.do(req, nil)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if errors.Is(err, ErrLoginRequired) {
+				t.Fatalf("unexpected login required error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientUniFiOSPrefix(t *testing.T) {
+	var tests = []struct {
+		desc string
+		csrf bool
+		path string
+		err  error
+	}{
+		{
+			desc: "classic not found",
+			path: "/api/s/default/stat/device",
+			err:  errors.New("unexpected HTTP status code: 404"),
+		},
+		{
+			desc: "UniFi OS not found",
+			csrf: true,
+			path: "/api/s/default/stat/device",
+			err:  errors.New("must use the /proxy/network prefix"),
+		},
+		{
+			desc: "UniFi OS other path",
+			csrf: true,
+			path: "/proxy/network/api/s/default/stat/device",
+			err:  errors.New("unexpected HTTP status code: 404"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.csrf {
+					w.Header().Set("X-CSRF-Token", "foo")
+				}
+				w.Header().Set("Content-Type", jsonContentType)
+				w.WriteHeader(http.StatusNotFound)
+			})
+			defer done()
+
+			req, err := c.newRequest(http.MethodGet, tt.path, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = c.do(req, nil)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+
+			var perr *PrefixError
+			if want, got := tt.csrf && strings.HasPrefix(tt.path, "/api/"), errors.As(err, &perr); want != got {
+				t.Fatalf("unexpected PrefixError:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		status   int
+		body     string
+		apiErr   *APIError
+		sentinel error
+	}{
+		{
+			desc: "OK",
+			body: `{"meta":{"rc":"ok"},"data":[]}`,
+		},
+		{
+			desc: "no meta",
+			body: `{"data":[]}`,
+		},
+		{
+			desc: "login required",
+			body: `{"meta":{"rc":"error","msg":"api.err.LoginRequired"},"data":[]}`,
+			apiErr: &APIError{
+				StatusCode: http.StatusOK,
+				Code:       "error",
+				Message:    "api.err.LoginRequired",
+			},
+			sentinel: ErrLoginRequired,
+		},
+		{
+			desc: "no permission",
+			body: `{"meta":{"rc":"error","msg":"api.err.NoPermission"},"data":[]}`,
+			apiErr: &APIError{
+				StatusCode: http.StatusOK,
+				Code:       "error",
+				Message:    "api.err.NoPermission",
+			},
+			sentinel: ErrPermissionDenied,
+		},
+		{
+			desc: "no message",
+			body: `{"meta":{"rc":"error"}}`,
+			apiErr: &APIError{
+				StatusCode: http.StatusOK,
+				Code:       "error",
+			},
+		},
+		{
+			desc:   "bad request",
+			status: http.StatusBadRequest,
+			body:   `{"meta":{"rc":"error","msg":"api.err.UnknownCommand"},"data":[]}`,
+			apiErr: &APIError{
+				StatusCode: http.StatusBadRequest,
+				Code:       "error",
+				Message:    "api.err.UnknownCommand",
+			},
+		},
+		{
+			desc:   "unauthorized",
+			status: http.StatusUnauthorized,
+			body:   `{"meta":{"rc":"error","msg":"api.err.LoginRequired"},"data":[]}`,
+			apiErr: &APIError{
+				StatusCode: http.StatusUnauthorized,
+				Code:       "error",
+				Message:    "api.err.LoginRequired",
+			},
+			sentinel: ErrLoginRequired,
+		},
+		{
+			desc:   "forbidden without meta",
+			status: http.StatusForbidden,
+			apiErr: &APIError{
+				StatusCode: http.StatusForbidden,
+			},
+			sentinel: ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", jsonContentType)
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			})
+			defer done()
+
+			req, err := c.newRequest(http.MethodGet, "/api/s/default/stat/device", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var v struct {
+				Data []interface{} `json:"data"`
+			}
+
+			_, err = c.do(req, &v)
+			if tt.apiErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var aerr *APIError
+			if !errors.As(err, &aerr) {
+				t.Fatalf("expected *APIError, but got: %#v", err)
+			}
+
+			if want, got := tt.apiErr, aerr; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected APIError:\n- want: %#v\n-  got: %#v", want, got)
+			}
+
+			for _, sentinel := range []error{ErrLoginRequired, ErrPermissionDenied} {
+				if want, got := sentinel == tt.sentinel, errors.Is(err, sentinel); want != got {
+					t.Fatalf("unexpected match for %v:\n- want: %v\n-  got: %v", sentinel, want, got)
+				}
+			}
+		})
+	}
+}
+
 func TestClientBadJSON(t *testing.T) {
 	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", jsonContentType)
@@ -70,6 +357,207 @@ func TestClientBadJSON(t *testing.T) {
 	}
 }
 
+func TestClientStrictJSON(t *testing.T) {
+	var tests = []struct {
+		desc   string
+		body   string
+		strict bool
+		fn     func(c *Client) error
+		err    error
+	}{
+		{
+			desc:   "lenient unknown field",
+			body:   `{"meta":{"rc":"ok"},"data":[{"name":"default","foo":1}]}`,
+			strict: false,
+			fn: func(c *Client) error {
+				_, err := c.Sites()
+				return err
+			},
+		},
+		{
+			desc:   "strict OK",
+			body:   `{"meta":{"rc":"ok"},"data":[{"name":"default"}]}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.Sites()
+				return err
+			},
+		},
+		{
+			desc:   "strict unknown field",
+			body:   `{"meta":{"rc":"ok"},"data":[{"name":"default","foo":1}]}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.Sites()
+				return err
+			},
+			err: errors.New(`unknown field "foo"`),
+		},
+		{
+			desc:   "strict unknown top-level field",
+			body:   `{"meta":{"rc":"ok"},"data":[],"foo":1}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.Sites()
+				return err
+			},
+			err: errors.New(`unknown field "foo"`),
+		},
+		{
+			desc:   "strict unknown raw field",
+			body:   `{"meta":{"rc":"ok"},"data":[{"inform_ip":"192.168.1.1","foo":1}]}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.Devices("default")
+				return err
+			},
+			err: errors.New(`unknown field "foo"`),
+		},
+		{
+			desc:   "strict single value OK",
+			body:   `{"meta":{"rc":"ok"},"data":[{"hostname":"unifi","version":"5.6.29"}]}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.SysInfo("default")
+				return err
+			},
+		},
+		{
+			desc:   "lenient single value unknown field",
+			body:   `{"meta":{"rc":"ok"},"data":[{"hostname":"unifi","foo":1}]}`,
+			strict: false,
+			fn: func(c *Client) error {
+				_, err := c.SysInfo("default")
+				return err
+			},
+		},
+		{
+			desc:   "strict single value unknown field",
+			body:   `{"meta":{"rc":"ok"},"data":[{"hostname":"unifi","foo":1}]}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.SysInfo("default")
+				return err
+			},
+			err: errors.New(`unknown field "foo"`),
+		},
+		{
+			desc:   "strict single object unknown field",
+			body:   `{"meta":{"rc":"ok"},"data":{"hostname":"unifi","foo":1}}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.SysInfo("default")
+				return err
+			},
+			err: errors.New(`unknown field "foo"`),
+		},
+		{
+			desc:   "strict single value not found",
+			body:   `{"meta":{"rc":"ok"},"data":[]}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.SysInfo("default")
+				return err
+			},
+			err: ErrNotFound,
+		},
+		{
+			desc:   "strict single raw value unknown field",
+			body:   `{"meta":{"rc":"ok"},"data":[{"inform_ip":"192.168.1.1","mac":"de:ad:be:ef:de:ad","foo":1}]}`,
+			strict: true,
+			fn: func(c *Client) error {
+				_, err := c.DeviceByMAC("default", net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad})
+				return err
+			},
+			err: errors.New(`unknown field "foo"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", jsonContentType)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			defer done()
+			c.StrictJSON = tt.strict
+
+			err := tt.fn(c)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeResponseFields(t *testing.T) {
+	type page struct {
+		Total int `json:"total"`
+	}
+
+	type response struct {
+		page
+		Data []int `json:"data"`
+	}
+
+	var tests = []struct {
+		desc string
+		b    string
+		want response
+		err  error
+	}{
+		{
+			desc: "case-insensitive",
+			b:    `{"Meta":{"rc":"ok"},"DATA":[1,2]}`,
+			want: response{Data: []int{1, 2}},
+		},
+		{
+			desc: "embedded",
+			b:    `{"meta":{"rc":"ok"},"data":[1],"total":2}`,
+			want: response{page: page{Total: 2}, Data: []int{1}},
+		},
+		{
+			desc: "unknown",
+			b:    `{"meta":{"rc":"ok"},"data":[1],"foo":2}`,
+			err:  errors.New(`unknown field "foo"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			// Strict mode must accept whatever encoding/json accepts, other
+			// than unknown fields.
+			var want response
+			if err := json.Unmarshal([]byte(tt.b), &want); err != nil {
+				t.Fatalf("failed to unmarshal with encoding/json: %v", err)
+			}
+
+			var got response
+			meta, err := decodeResponse([]byte(tt.b), &got, true)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if meta == nil || meta.RC != "ok" {
+				t.Fatalf("unexpected meta block: %+v", meta)
+			}
+			if !reflect.DeepEqual(tt.want, got) || !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected response:\n- want: %+v\n-  got: %+v", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestClientRetainsCookies(t *testing.T) {
 	const cookieName = "foo"
 	wantCookie := &http.Cookie{
@@ -134,6 +622,608 @@ func TestClientLogin(t *testing.T) {
 	}
 }
 
+func TestClientLoginUniFiOS(t *testing.T) {
+	const (
+		wantUsername = "test"
+		wantPassword = "test"
+	)
+
+	wantBody := &login{
+		Username: wantUsername,
+		Password: wantPassword,
+	}
+
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		// Every response from a UniFi OS console carries a CSRF token.
+		switch r.URL.Path {
+		case "/api/login":
+			w.Header().Set("X-CSRF-Token", "login")
+			w.Header().Set("Content-Type", jsonContentType)
+			w.WriteHeader(http.StatusNotFound)
+		case "/api/auth/login":
+			w.Header().Set("X-CSRF-Token", "foo")
+			testHandler(t, http.MethodPost, "/api/auth/login", wantBody, nil)(w, r)
+		case "/proxy/network/api/self/sites":
+			w.Header().Set("X-CSRF-Token", "foo")
+			w.Header().Set("X-Updated-CSRF-Token", "bar")
+			testHandler(t, http.MethodGet, "/proxy/network/api/self/sites", nil,
+				map[string]interface{}{"data": []*Site{}},
+			)(w, r)
+		case "/proxy/network/api/s/default/cmd/devmgr", "/api/auth/logout":
+			if want, got := "bar", r.Header.Get("X-CSRF-Token"); want != got {
+				t.Fatalf("unexpected CSRF token:\n- want: %v\n-  got: %v", want, got)
+			}
+
+			w.Header().Set("X-CSRF-Token", "bar")
+			w.Header().Set("Content-Type", jsonContentType)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer done()
+
+	if err := c.Login(wantUsername, wantPassword); err != nil {
+		t.Fatalf("unexpected error from Client.Login: %v", err)
+	}
+	if !c.UniFiOS {
+		t.Fatal("Client.Login did not detect UniFi OS console")
+	}
+
+	if _, err := c.Sites(); err != nil {
+		t.Fatalf("unexpected error from Client.Sites: %v", err)
+	}
+	if err := c.OptimizeChannels("default"); err != nil {
+		t.Fatalf("unexpected error from Client.OptimizeChannels: %v", err)
+	}
+	if err := c.Logout(); err != nil {
+		t.Fatalf("unexpected error from Client.Logout: %v", err)
+	}
+
+	want := []string{
+		"POST /api/login",
+		"POST /api/auth/login",
+		"GET /proxy/network/api/self/sites",
+		"POST /proxy/network/api/s/default/cmd/devmgr",
+		"POST /api/auth/logout",
+	}
+
+	if got := paths; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected requests:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestUniFiOSPath(t *testing.T) {
+	var tests = []struct {
+		path string
+		want string
+	}{
+		{path: "/api/s/default/stat/device", want: "/proxy/network/api/s/default/stat/device"},
+		{path: "/api/self/sites", want: "/proxy/network/api/self/sites"},
+		{path: "/status", want: "/proxy/network/status"},
+		{path: "/api/auth/login", want: "/api/auth/login"},
+		{path: "/api/system", want: "/api/system"},
+		{path: "/api/system/reboot", want: "/api/system/reboot"},
+		{path: "/api/systemd", want: "/proxy/network/api/systemd"},
+		{path: "/proxy/network/api/s/default/stat/device", want: "/proxy/network/api/s/default/stat/device"},
+	}
+
+	for _, tt := range tests {
+		if got := uniFiOSPath(tt.path); tt.want != got {
+			t.Fatalf("unexpected path for %q:\n- want: %v\n-  got: %v", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestClientLogout(t *testing.T) {
+	const cookieName = "unifises"
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if want, got := http.MethodPost, r.Method; want != got {
+			t.Fatalf("unexpected HTTP method:\n- want: %v\n-  got: %v", want, got)
+		}
+
+		switch r.URL.Path {
+		case "/api/login":
+			http.SetCookie(w, &http.Cookie{Name: cookieName, Value: "bar"})
+		case "/api/logout":
+			if _, err := r.Cookie(cookieName); err != nil {
+				t.Fatalf("session cookie not sent with logout: %v", err)
+			}
+		default:
+			t.Fatalf("unexpected URL path: %v", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", jsonContentType)
+	})
+	defer done()
+
+	if err := c.Logout(); !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("unexpected error before login:\n- want: %v\n-  got: %v", ErrLoginRequired, err)
+	}
+
+	if err := c.Login("test", "test"); err != nil {
+		t.Fatalf("unexpected error from Client.Login: %v", err)
+	}
+
+	if err := c.Logout(); err != nil {
+		t.Fatalf("unexpected error from Client.Logout: %v", err)
+	}
+
+	if cookies := c.client.Jar.Cookies(c.apiURL); len(cookies) != 0 {
+		t.Fatalf("unexpected cookies after logout: %v", cookies)
+	}
+
+	if err := c.Logout(); !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("unexpected error after logout:\n- want: %v\n-  got: %v", ErrLoginRequired, err)
+	}
+}
+
+func TestClientLogoutRestoredSession(t *testing.T) {
+	const cookieName = "unifises"
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if want, got := "/api/logout", r.URL.Path; want != got {
+			t.Fatalf("unexpected URL path:\n- want: %v\n-  got: %v", want, got)
+		}
+		if _, err := r.Cookie(cookieName); err != nil {
+			t.Fatalf("session cookie not sent with logout: %v", err)
+		}
+
+		w.Header().Set("Content-Type", jsonContentType)
+	})
+	defer done()
+
+	// Restore a session from a jar which also holds cookies for another host.
+	jar := c.client.Jar
+	other := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+	jar.SetCookies(c.apiURL, []*http.Cookie{{Name: cookieName, Value: "bar", Path: "/"}})
+	jar.SetCookies(other, []*http.Cookie{{Name: "other", Value: "baz", Path: "/"}})
+
+	if err := c.Logout(); err != nil {
+		t.Fatalf("unexpected error from Client.Logout: %v", err)
+	}
+
+	if c.client.Jar != jar {
+		t.Fatal("cookie jar was replaced by Client.Logout")
+	}
+	if cookies := jar.Cookies(c.apiURL); len(cookies) != 0 {
+		t.Fatalf("unexpected cookies after logout: %v", cookies)
+	}
+	if cookies := jar.Cookies(other); len(cookies) != 1 {
+		t.Fatalf("unexpected cookies for other host after logout: %v", cookies)
+	}
+}
+
+func TestNew(t *testing.T) {
+	const addr = "https://unifi.example.com:8443"
+
+	hc := &http.Client{Timeout: time.Second}
+
+	var tests = []struct {
+		desc      string
+		opts      []Option
+		timeout   time.Duration
+		userAgent string
+		unifiOS   bool
+	}{
+		{
+			desc:      "defaults",
+			timeout:   10 * time.Second,
+			userAgent: userAgent,
+		},
+		{
+			desc:      "HTTP client",
+			opts:      []Option{WithHTTPClient(hc)},
+			timeout:   time.Second,
+			userAgent: userAgent,
+		},
+		{
+			desc: "all",
+			opts: []Option{
+				WithHTTPClient(hc),
+				WithTimeout(5 * time.Second),
+				WithUserAgent("foo"),
+				WithUniFiOS(true),
+			},
+			timeout:   5 * time.Second,
+			userAgent: "foo",
+			unifiOS:   true,
+		},
+		{
+			desc:      "timeout only",
+			opts:      []Option{WithTimeout(2 * time.Second)},
+			timeout:   2 * time.Second,
+			userAgent: userAgent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, err := New(addr, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create Client: %v", err)
+			}
+
+			if want, got := tt.timeout, c.client.Timeout; want != got {
+				t.Fatalf("unexpected timeout:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := tt.userAgent, c.UserAgent; want != got {
+				t.Fatalf("unexpected user agent:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := tt.unifiOS, c.UniFiOS; want != got {
+				t.Fatalf("unexpected UniFi OS mode:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := addr, c.apiURL.String(); want != got {
+				t.Fatalf("unexpected API URL:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+
+	// The caller's HTTP client must not be modified by WithTimeout.
+	if want, got := time.Second, hc.Timeout; want != got {
+		t.Fatalf("HTTP client timeout was modified:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientUserAgent(t *testing.T) {
+	var tests = []struct {
+		desc string
+		ua   string
+		want string
+	}{
+		{
+			desc: "default",
+			ua:   userAgent,
+			want: "mdlayher/unifi/" + Version,
+		},
+		{
+			desc: "custom",
+			ua:   "foo/1.0",
+			want: "foo/1.0",
+		},
+		{
+			desc: "empty",
+			want: "mdlayher/unifi/" + Version,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if want, got := tt.want, r.Header.Get("User-Agent"); want != got {
+					t.Fatalf("unexpected User-Agent:\n- want: %v\n-  got: %v", want, got)
+				}
+
+				w.Header().Set("Content-Type", jsonContentType)
+				_, _ = w.Write([]byte(`{"data":[]}`))
+			})
+			defer done()
+
+			c.UserAgent = tt.ua
+			if _, err := c.Sites(); err != nil {
+				t.Fatalf("unexpected error from Client.Sites: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		method   string
+		failures int
+		reset    bool
+		attempts int
+		ok       bool
+	}{
+		{
+			desc:     "GET succeeds after retries",
+			method:   http.MethodGet,
+			failures: 2,
+			attempts: 3,
+			ok:       true,
+		},
+		{
+			desc:     "GET succeeds after connection reset",
+			method:   http.MethodGet,
+			failures: 1,
+			reset:    true,
+			attempts: 2,
+			ok:       true,
+		},
+		{
+			desc:     "GET attempts exhausted",
+			method:   http.MethodGet,
+			failures: 5,
+			attempts: 3,
+		},
+		{
+			desc:     "POST not retried",
+			method:   http.MethodPost,
+			failures: 1,
+			attempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts int
+			)
+
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+
+				if attempts <= tt.failures {
+					if tt.reset {
+						conn, _, err := w.(http.Hijacker).Hijack()
+						if err != nil {
+							t.Fatalf("failed to hijack connection: %v", err)
+						}
+						_ = conn.Close()
+						return
+					}
+
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				w.Header().Set("Content-Type", jsonContentType)
+				_, _ = w.Write([]byte(`{"data":[]}`))
+			}))
+			defer s.Close()
+
+			c, err := New(s.URL, WithRetry(3, time.Millisecond))
+			if err != nil {
+				t.Fatalf("failed to create Client: %v", err)
+			}
+
+			req, err := c.newRequest(tt.method, "/api/self/sites", struct{}{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = c.do(req, nil)
+			if want, got := tt.ok, err == nil; want != got {
+				t.Fatalf("unexpected success:\n- want: %v\n-  got: %v (%v)", want, got, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if want, got := tt.attempts, attempts; want != got {
+				t.Fatalf("unexpected number of attempts:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientRetryContextCanceled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer s.Close()
+
+	c, err := New(s.URL, WithRetry(10, time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create Client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := c.newRequest(http.MethodGet, "/api/self/sites", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.do(req.WithContext(ctx), nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestClientAutoRelogin(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		opts    []Option
+		expired func(requests int) bool
+		logins  int
+		ok      bool
+	}{
+		{
+			desc: "disabled",
+			expired: func(requests int) bool {
+				return requests == 1
+			},
+		},
+		{
+			desc: "session expired once",
+			opts: []Option{WithAutoRelogin("test", "test")},
+			expired: func(requests int) bool {
+				return requests == 1
+			},
+			logins: 1,
+			ok:     true,
+		},
+		{
+			desc: "session always expired",
+			opts: []Option{WithAutoRelogin("test", "test")},
+			expired: func(requests int) bool {
+				return true
+			},
+			logins: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var (
+				mu               sync.Mutex
+				logins, requests int
+			)
+
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				w.Header().Set("Content-Type", jsonContentType)
+
+				switch r.URL.Path {
+				case "/api/login":
+					testHandler(t, http.MethodPost, "/api/login", &login{Username: "test", Password: "test"}, nil)(w, r)
+					logins++
+				case "/api/s/default/rest/user/abcdef":
+					// The request body must be sent intact each time.
+					b, err := io.ReadAll(r.Body)
+					if err != nil {
+						t.Fatalf("failed to read request body: %v", err)
+					}
+					if want, got := `{"name":"foo"}`, strings.TrimSpace(string(b)); want != got {
+						t.Fatalf("unexpected request body:\n- want: %v\n-  got: %v", want, got)
+					}
+					requests++
+
+					if tt.expired(requests) {
+						w.WriteHeader(http.StatusUnauthorized)
+						_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"api.err.LoginRequired"},"data":[]}`))
+						return
+					}
+
+					_, _ = w.Write([]byte(`{"meta":{"rc":"ok"},"data":[]}`))
+				default:
+					t.Fatalf("unexpected URL path: %v", r.URL.Path)
+				}
+			}))
+			defer s.Close()
+
+			c, err := New(s.URL, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create Client: %v", err)
+			}
+
+			name := "foo"
+			err = c.UpdateStation("default", "abcdef", StationUpdate{Name: &name})
+			if want, got := tt.ok, err == nil; want != got {
+				t.Fatalf("unexpected success:\n- want: %v\n-  got: %v (%v)", want, got, err)
+			}
+			if !tt.ok && !errors.Is(err, ErrLoginRequired) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrLoginRequired, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if want, got := tt.logins, logins; want != got {
+				t.Fatalf("unexpected number of logins:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientAutoReloginConcurrent(t *testing.T) {
+	const n = 16
+
+	var (
+		mu     sync.Mutex
+		valid  bool
+		logins int
+	)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", jsonContentType)
+
+		switch r.URL.Path {
+		case "/api/login":
+			testHandler(t, http.MethodPost, "/api/login", &login{Username: "test", Password: "test"}, nil)(w, r)
+			logins++
+			valid = true
+		case "/api/self/sites":
+			if !valid {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"api.err.LoginRequired"},"data":[]}`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"meta":{"rc":"ok"},"data":[]}`))
+		default:
+			t.Fatalf("unexpected URL path: %v", r.URL.Path)
+		}
+	}))
+	defer s.Close()
+
+	c, err := New(s.URL, WithAutoRelogin("test", "test"))
+	if err != nil {
+		t.Fatalf("failed to create Client: %v", err)
+	}
+
+	// Every request which observes the expired session must share a single
+	// login.
+	var wg sync.WaitGroup
+	errC := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := c.newRequest(http.MethodGet, "/api/self/sites", nil)
+			if err != nil {
+				errC <- err
+				return
+			}
+
+			_, err = c.do(req, nil)
+			errC <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errC)
+
+	for err := range errC {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if want, got := 1, logins; want != got {
+		t.Fatalf("unexpected number of logins:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	hc := &http.Client{}
+
+	c, err := NewClient("https://unifi.example.com:8443/", hc)
+	if err != nil {
+		t.Fatalf("failed to create Client: %v", err)
+	}
+
+	if c.client != hc {
+		t.Fatal("Client does not use the specified HTTP client")
+	}
+	if want, got := userAgent, c.UserAgent; want != got {
+		t.Fatalf("unexpected user agent:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
 func TestInsecureHTTPClient(t *testing.T) {
 	timeout := 5 * time.Second
 	c := InsecureHTTPClient(timeout)
@@ -153,7 +1243,7 @@ func TestInsecureHTTPClient(t *testing.T) {
 func testClient(t *testing.T, fn func(w http.ResponseWriter, r *http.Request)) (*Client, func()) {
 	s := httptest.NewServer(http.HandlerFunc(fn))
 
-	c, err := NewClient(s.URL, nil)
+	c, err := New(s.URL)
 	if err != nil {
 		t.Fatalf("error creating Client: %v", err)
 	}
@@ -219,3 +1309,66 @@ func testHandler(t *testing.T, method string, path string, body interface{}, out
 		}
 	}
 }
+
+func TestSingleValueUnmarshalJSON(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    string
+	}{
+		{desc: "array", b: `[{"name":"foo"},{"name":"bar"}]`},
+		{desc: "object", b: `{"name":"foo"}`},
+		{desc: "whitespace", b: " \n[{\"name\":\"foo\"}]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var v struct {
+				Name string `json:"name"`
+			}
+			if err := (singleValue{v: &v}).UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := "foo", v.Name; want != got {
+				t.Fatalf("unexpected name:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		"/api/s/default/stat/event",
+		&eventQuery{Within: 1, Sort: "-time"},
+		map[string]interface{}{"data": []interface{}{}},
+	))
+	defer done()
+	c.pollInterval = 5 * time.Millisecond
+
+	ch, err := c.PresenceStream(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("unexpected error from Client.PresenceStream: %v", err)
+	}
+
+	// Close must be safe to call more than once.
+	for i := 0; i < 2; i++ {
+		if err := c.Close(); err != nil {
+			t.Fatalf("unexpected error from Client.Close: %v", err)
+		}
+	}
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("unexpected PresenceEvent after Client.Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PresenceStream was not stopped by Client.Close")
+	}
+
+	if _, err := c.Events("default"); err != ErrClosed {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrClosed, err)
+	}
+}
@@ -0,0 +1,58 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// A Dashboard is a custom dashboard defined in the UniFi Controller's web
+// interface.
+type Dashboard struct {
+	ID          string `json:"_id,omitempty"`
+	Description string `json:"desc,omitempty"`
+	IsPublic    bool   `json:"is_public"`
+	Name        string `json:"name"`
+	SiteID      string `json:"site_id,omitempty"`
+
+	// Modules contains the Dashboard's widgets verbatim, because their
+	// structure is complex and varies between UniFi Controller versions.
+	Modules json.RawMessage `json:"modules,omitempty"`
+}
+
+// Dashboards returns all of the custom Dashboards for a specified site name.
+func (c *Client) Dashboards(siteName string) ([]*Dashboard, error) {
+	return getList[Dashboard](context.Background(), c, siteName, "rest/dashboard")
+}
+
+// SaveDashboard saves a custom Dashboard for a specified site name.  If the
+// Dashboard has no ID, it is created and its ID is set from the UniFi
+// Controller's response.  Otherwise, the existing Dashboard is replaced.
+func (c *Client) SaveDashboard(siteName string, d *Dashboard) error {
+	method := http.MethodPut
+	path := fmt.Sprintf("/api/s/%s/rest/dashboard/%s", siteName, d.ID)
+	if d.ID == "" {
+		method = http.MethodPost
+		path = fmt.Sprintf("/api/s/%s/rest/dashboard", siteName)
+	}
+
+	var v struct {
+		Dashboards []*Dashboard `json:"data"`
+	}
+
+	req, err := c.newRequest(method, path, d)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.do(req, &v); err != nil {
+		return err
+	}
+
+	if d.ID == "" && len(v.Dashboards) > 0 {
+		d.ID = v.Dashboards[0].ID
+	}
+
+	return nil
+}
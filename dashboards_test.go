@@ -0,0 +1,98 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClientDashboards(t *testing.T) {
+	const wantSite = "default"
+
+	wantDashboard := &Dashboard{
+		ID:      "abcdef1234567890",
+		Name:    "NOC",
+		Modules: json.RawMessage(`[{"widget":"traffic","restrict":"wan"}]`),
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/rest/dashboard", wantSite),
+		nil,
+		struct {
+			Dashboards []*Dashboard `json:"data"`
+		}{Dashboards: []*Dashboard{wantDashboard}},
+	))
+	defer done()
+
+	dashboards, err := c.Dashboards(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.Dashboards: %v", err)
+	}
+
+	if want, got := []*Dashboard{wantDashboard}, dashboards; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Dashboards:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientSaveDashboard(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	var tests = []struct {
+		desc   string
+		id     string
+		method string
+		path   string
+	}{
+		{
+			desc:   "create",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/api/s/%s/rest/dashboard", wantSite),
+		},
+		{
+			desc:   "update",
+			id:     wantID,
+			method: http.MethodPut,
+			path:   fmt.Sprintf("/api/s/%s/rest/dashboard/%s", wantSite, wantID),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := &Dashboard{
+				ID:      tt.id,
+				Name:    "NOC",
+				Modules: json.RawMessage(`[{"widget":"traffic"}]`),
+			}
+
+			c, done := testClient(t, testHandler(
+				t,
+				tt.method,
+				tt.path,
+				&Dashboard{
+					ID:      tt.id,
+					Name:    "NOC",
+					Modules: json.RawMessage(`[{"widget":"traffic"}]`),
+				},
+				struct {
+					Dashboards []*Dashboard `json:"data"`
+				}{Dashboards: []*Dashboard{{ID: wantID, Name: "NOC"}}},
+			))
+			defer done()
+
+			if err := c.SaveDashboard(wantSite, d); err != nil {
+				t.Fatalf("unexpected error from Client.SaveDashboard: %v", err)
+			}
+
+			if want, got := wantID, d.ID; want != got {
+				t.Fatalf("unexpected Dashboard ID:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
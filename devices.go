@@ -1,60 +1,983 @@
 package unifi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Devices returns all of the Devices for a specified site name.
 func (c *Client) Devices(siteName string) ([]*Device, error) {
-	var v struct {
-		Devices []*Device `json:"data"`
+	return c.devices(context.Background(), siteName)
+}
+
+// devices retrieves all of the Devices for a specified site name.
+func (c *Client) devices(ctx context.Context, siteName string) ([]*Device, error) {
+	return getList[Device](ctx, c, siteName, "stat/device")
+}
+
+// DeviceByMAC returns the Device with the specified MAC address for a
+// specified site name, without retrieving every Device on the site.  If no
+// such Device exists, ErrNotFound is returned.
+func (c *Client) DeviceByMAC(siteName string, mac net.HardwareAddr) (*Device, error) {
+	if len(mac) == 0 {
+		return nil, errors.New("device MAC address must not be empty")
+	}
+
+	return getOne[Device](context.Background(), c, siteName, "stat/device/"+mac.String())
+}
+
+// devicesPageSize is the number of Devices requested per page by
+// Client.DevicesEach.
+const devicesPageSize = 100
+
+// DevicesPage returns a page of at most limit Devices for a specified site
+// name, beginning at offset start.  Retrieving Devices in pages avoids
+// decoding the very large response returned for sites with many Devices.
+func (c *Client) DevicesPage(siteName string, start int, limit int) ([]*Device, error) {
+	return queryList[Device](context.Background(), c, siteName, "stat/device", &pageQuery{
+		Start: start,
+		Limit: limit,
+	})
+}
+
+// DevicesEach calls fn for each of the Devices for a specified site name.
+// Devices are retrieved in pages, so that the Devices for very large sites
+// need not be held in memory at once.
+//
+// Some UniFi Controller versions ignore the paging parameters and return
+// every Device in each response.  DevicesEach detects this, and calls fn only
+// once for each Device.
+//
+// If fn returns an error, iteration stops and that error is returned.
+func (c *Client) DevicesEach(siteName string, fn func(d *Device) error) error {
+	var firstID string
+	for start := 0; ; {
+		devices, err := c.DevicesPage(siteName, start, devicesPageSize)
+		if err != nil {
+			return err
+		}
+
+		// A page which begins with the same Device as the previous page
+		// indicates that the paging parameters were ignored, and that every
+		// Device has already been visited.
+		if len(devices) > 0 {
+			if start > 0 && devices[0].ID == firstID {
+				return nil
+			}
+			firstID = devices[0].ID
+		}
+
+		for _, d := range devices {
+			if err := fn(d); err != nil {
+				return err
+			}
+		}
+
+		// A short page indicates that no Devices remain, and a page longer
+		// than requested indicates that every Device was returned at once.
+		if len(devices) != devicesPageSize {
+			return nil
+		}
+
+		start += len(devices)
+	}
+}
+
+// PendingDevices returns the Devices for a specified site name which are in
+// the process of being adopted, provisioned, or upgraded, and which are
+// expected to return to the connected state without intervention.  Devices
+// which are disconnected or which failed adoption are not included.
+func (c *Client) PendingDevices(siteName string) ([]*Device, error) {
+	devices, err := c.Devices(siteName)
+	if err != nil {
+		return nil, err
 	}
 
+	pending := make([]*Device, 0, len(devices))
+	for _, d := range devices {
+		switch d.State {
+		case StatePending, StateAdopting, StateProvisioning, StateUpgrading:
+			pending = append(pending, d)
+		}
+	}
+
+	return pending, nil
+}
+
+// WANLatency returns the uplink latency of the gateway for a specified site
+// name.  If the site has more than one gateway, the latency of the first
+// gateway reported by the UniFi Controller is returned.  If the site has no
+// gateway, an error wrapping ErrNotFound is returned.
+func (c *Client) WANLatency(siteName string) (time.Duration, error) {
+	devices, err := c.Devices(siteName)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range devices {
+		if d.Type == DeviceTypeGateway {
+			return d.Stats.UplinkLatency, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no gateway on site %q: %w", siteName, ErrNotFound)
+}
+
+// RestartDevice restarts the Device with the specified MAC address on a
+// specified site name, without waiting for it to return.  If the UniFi
+// Controller rejects the command, for example because no such Device is
+// adopted on the site, its error is returned.
+func (c *Client) RestartDevice(siteName string, mac net.HardwareAddr) error {
+	return c.deviceMACCommand(context.Background(), siteName, "restart", mac)
+}
+
+// AdoptDevice adopts the pending Device with the specified MAC address into
+// a specified site name.  Adoption completes asynchronously; use
+// Device.AdoptionState to follow its progress.  If the UniFi Controller
+// rejects the command, its error is returned.
+func (c *Client) AdoptDevice(siteName string, mac net.HardwareAddr) error {
+	return c.deviceMACCommand(context.Background(), siteName, "adopt", mac)
+}
+
+// ForgetDevice removes the Device with the specified MAC address from a
+// specified site name, so that it is no longer managed by the UniFi
+// Controller.  The Device is reset to factory defaults if it is connected.
+// If the UniFi Controller rejects the command, its error is returned.
+func (c *Client) ForgetDevice(siteName string, mac net.HardwareAddr) error {
+	const cmd = "delete-device"
+	if err := checkMAC("device", cmd, mac); err != nil {
+		return err
+	}
+
+	// Devices are removed by the site manager rather than the device manager.
+	return c.sitemgr(siteName, &deviceCommand{
+		Command: cmd,
+		MAC:     mac.String(),
+	})
+}
+
+// RestartDeviceAndWait restarts the Device with the specified MAC address for
+// a specified site name, and then polls the UniFi Controller until the Device
+// reports that it is connected again.
+//
+// If the Device does not return before timeout elapses, an error wrapping
+// ErrTimeout is returned which contains the last state observed for the
+// Device.  If ctx is canceled, its error is returned.
+func (c *Client) RestartDeviceAndWait(ctx context.Context, siteName string, mac string, timeout time.Duration) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return err
+	}
+	mac = hw.String()
+
+	// Note the Device's uptime before restarting it, so that a Device which
+	// comes back before we observe it going away can still be detected.
+	before, err := c.deviceStatus(ctx, siteName, mac)
+	if err != nil {
+		return err
+	}
+
+	if err := c.deviceMACCommand(ctx, siteName, "restart", hw); err != nil {
+		return err
+	}
+
+	// Bound the status requests by the timeout as well as the polling, so
+	// that a slow request cannot extend the wait.
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		last    = before
+		sawDown bool
+	)
+
+	err = PollUntil(tctx, c.pollInterval, 0, func() (bool, error) {
+		s, err := c.deviceStatus(tctx, siteName, mac)
+		if err != nil {
+			// The timeout may elapse during a request; report it as such.
+			if tctx.Err() != nil {
+				return false, nil
+			}
+
+			return false, err
+		}
+		last = s
+
+		if s.State != StateConnected {
+			sawDown = true
+			return false, nil
+		}
+
+		return sawDown || s.Uptime < before.Uptime, nil
+	})
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case tctx.Err() != nil:
+		return fmt.Errorf("%w waiting for device %s to restart, last observed state: %v",
+			ErrTimeout, mac, last.State)
+	default:
+		return err
+	}
+}
+
+// deviceStatus retrieves the raw status of a single device by MAC address.
+// If the device is not known to the UniFi Controller, it is reported as
+// disconnected.
+func (c *Client) deviceStatus(ctx context.Context, siteName string, mac string) (*deviceStatus, error) {
+	s, err := getOne[deviceStatus](ctx, c, siteName, "stat/device/"+mac)
+	if errors.Is(err, ErrNotFound) {
+		return &deviceStatus{}, nil
+	}
+
+	return s, err
+}
+
+// A deviceStatus is the subset of the raw structure of a Device needed to
+// track it through a restart.
+type deviceStatus struct {
+	State  DeviceState `json:"state"`
+	Uptime int         `json:"uptime"`
+}
+
+// devmgr issues a command to the UniFi Controller's device manager.
+func (c *Client) devmgr(ctx context.Context, siteName string, cmd *deviceCommand) error {
 	req, err := c.newRequest(
-		"GET",
-		fmt.Sprintf("/api/s/%s/stat/device", siteName),
-		nil,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/devmgr", siteName),
+		cmd,
 	)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	_, err = c.do(req.WithContext(ctx), nil)
+	return err
+}
+
+// A deviceCommand is a command sent to the UniFi Controller's device manager.
+type deviceCommand struct {
+	Command string `json:"cmd"`
+	MAC     string `json:"mac,omitempty"`
+}
+
+// deviceMACCommand issues a device manager command which applies to the
+// Device with a single MAC address on a site.
+func (c *Client) deviceMACCommand(ctx context.Context, siteName string, cmd string, mac net.HardwareAddr) error {
+	if err := checkMAC("device", cmd, mac); err != nil {
+		return err
+	}
+
+	return c.devmgr(ctx, siteName, &deviceCommand{
+		Command: cmd,
+		MAC:     mac.String(),
+	})
+}
+
+// sitemgr issues a command to the UniFi Controller's site manager.
+func (c *Client) sitemgr(siteName string, cmd *deviceCommand) error {
+	req, err := c.newRequest(
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/sitemgr", siteName),
+		cmd,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
+
+// ResetDeviceStats resets the statistics counters of the Device with the
+// specified MAC address on a specified site name.
+//
+// Not all UniFi Controller versions can reset a Device's statistics.  If the
+// UniFi Controller does not support it, an error wrapping ErrUnsupported is
+// returned.
+func (c *Client) ResetDeviceStats(siteName string, mac string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return err
+	}
+
+	err = c.devmgr(context.Background(), siteName, &deviceCommand{
+		Command: "reset-stats",
+		MAC:     hw.String(),
+	})
+	if isUnsupported(err) {
+		return fmt.Errorf("failed to reset statistics for device %s: %w: %w",
+			hw, ErrUnsupported, err)
+	}
+
+	return err
+}
+
+// SetDeviceConfigNetwork sets the management network configuration for the
+// Device with the specified ID on a specified site name.  If dhcp is true,
+// the Device obtains its address using DHCP, and ip, netmask, and gateway
+// must be empty.  Otherwise, the Device is configured with the specified
+// static IPv4 addresses, all of which are required.
+func (c *Client) SetDeviceConfigNetwork(siteName string, deviceID string, dhcp bool, ip, netmask, gateway string) error {
+	cn := map[string]interface{}{"type": "dhcp"}
+	if dhcp {
+		if ip != "" || netmask != "" || gateway != "" {
+			return fmt.Errorf("static addresses must not be specified for DHCP config network: ip %q, netmask %q, gateway %q",
+				ip, netmask, gateway)
+		}
+	} else {
+		for _, a := range []struct{ name, addr string }{
+			{name: "IP", addr: ip},
+			{name: "netmask", addr: netmask},
+			{name: "gateway", addr: gateway},
+		} {
+			if net.ParseIP(a.addr).To4() == nil {
+				return fmt.Errorf("invalid static config network %s: %q", a.name, a.addr)
+			}
+		}
+
+		cn = map[string]interface{}{
+			"type":    "static",
+			"ip":      ip,
+			"netmask": netmask,
+			"gateway": gateway,
+		}
+	}
+
+	return c.updateDevice(siteName, deviceID, func(d map[string]interface{}) error {
+		// Preserve any other configuration, such as DNS servers.
+		merged, _ := d["config_network"].(map[string]interface{})
+		if merged == nil {
+			merged = make(map[string]interface{})
+		}
+		for k, v := range cn {
+			merged[k] = v
+		}
+
+		d["config_network"] = merged
+		return nil
+	})
+}
+
+// SetRadioName renames the Radio named currentName on the Device with the
+// specified ID on a specified site name.  All other settings for the Radio
+// are preserved.  If the Device has no Radio named currentName, an error is
+// returned.
+func (c *Client) SetRadioName(siteName string, deviceID string, currentName string, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("radio name must not be empty")
+	}
+
+	return c.updateDevice(siteName, deviceID, func(d map[string]interface{}) error {
+		radios, _ := d["radio_table"].([]interface{})
+		for _, r := range radios {
+			radio, ok := r.(map[string]interface{})
+			if !ok || radio["name"] != currentName {
+				continue
+			}
+
+			radio["name"] = newName
+			return nil
+		}
+
+		return fmt.Errorf("device %s has no radio named %q", deviceID, currentName)
+	})
+}
+
+// SetDeviceSSH overrides the SSH credentials used to manage the Device with
+// the specified ID on a specified site name, in place of the credentials in
+// the site's device authentication settings.  username must not be empty.
+//
+// The password is sent only to the UniFi Controller, and is never included
+// in a returned error.
+func (c *Client) SetDeviceSSH(siteName string, deviceID string, username string, password string) error {
+	if username == "" {
+		return errors.New("SSH username must not be empty")
+	}
+
+	return c.updateDevice(siteName, deviceID, func(d map[string]interface{}) error {
+		d["x_ssh_username"] = username
+		d["x_ssh_password"] = password
+		return nil
+	})
+}
+
+// RenameDevice sets the name of the Device with the specified ID on a
+// specified site name.  name must not be empty.  To rename a Device given
+// only its MAC address, use RenameDeviceByMAC.
+func (c *Client) RenameDevice(siteName string, deviceID string, name string) error {
+	if name == "" {
+		return errors.New("device name must not be empty")
+	}
+
+	req, err := c.newRequest(
+		http.MethodPut,
+		fmt.Sprintf("/api/s/%s/rest/device/%s", siteName, deviceID),
+		&deviceRename{Name: name},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
+
+// RenameDeviceByMAC sets the name of the Device with the specified MAC
+// address on a specified site name, by first resolving the MAC address to
+// the Device's ID.  If no such Device exists, ErrNotFound is returned.
+func (c *Client) RenameDeviceByMAC(siteName string, mac net.HardwareAddr, name string) error {
+	if name == "" {
+		return errors.New("device name must not be empty")
+	}
+
+	d, err := c.DeviceByMAC(siteName, mac)
+	if err != nil {
+		return err
+	}
+
+	return c.RenameDevice(siteName, d.ID, name)
+}
+
+// A deviceRename is the raw structure used to rename a Device.
+type deviceRename struct {
+	Name string `json:"name"`
+}
+
+// poeModes is the set of PoE modes which may be applied to a switch port.
+var poeModes = map[string]struct{}{
+	"auto":        {},
+	"off":         {},
+	"pasv24":      {},
+	"passthrough": {},
+}
+
+// SetPortPoE sets the PoE mode of the port with the specified index on the
+// switch with the specified MAC address on a specified site name.  mode must
+// be one of "auto", "off", "pasv24", or "passthrough".  Setting the mode to
+// "off" and back to "auto" power-cycles a powered device.
+//
+// The overrides for all other ports on the switch are preserved.  If no such
+// switch exists, ErrNotFound is returned.
+func (c *Client) SetPortPoE(siteName string, deviceMAC net.HardwareAddr, portIdx int, mode string) error {
+	if _, ok := poeModes[mode]; !ok {
+		return fmt.Errorf("invalid PoE mode: %q", mode)
+	}
+	if portIdx < 1 {
+		return fmt.Errorf("invalid port index: %d", portIdx)
+	}
+
+	d, err := c.DeviceByMAC(siteName, deviceMAC)
+	if err != nil {
+		return err
+	}
+
+	// The UniFi Controller replaces the port overrides wholesale, so the
+	// change must be merged into the existing overrides.
+	return c.updateDevice(siteName, d.ID, func(d map[string]interface{}) error {
+		overrides, _ := d["port_overrides"].([]interface{})
+		for _, o := range overrides {
+			po, ok := o.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			// JSON numbers are decoded as float64.
+			if idx, _ := po["port_idx"].(float64); int(idx) == portIdx {
+				po["poe_mode"] = mode
+				return nil
+			}
+		}
+
+		d["port_overrides"] = append(overrides, map[string]interface{}{
+			"port_idx": portIdx,
+			"poe_mode": mode,
+		})
+		return nil
+	})
+}
+
+// An LEDMode is the mode of a Device's status LED.
+type LEDMode int
+
+// List of possible LEDMode values.
+const (
+	LEDModeDefault LEDMode = iota
+	LEDModeOn
+	LEDModeOff
+)
+
+// String returns the string representation of an LEDMode, as used by the
+// UniFi Controller.
+func (m LEDMode) String() string {
+	switch m {
+	case LEDModeOn:
+		return "on"
+	case LEDModeOff:
+		return "off"
+	default:
+		return "default"
+	}
+}
+
+// SetDeviceLED sets the LED mode of the Device with the specified ID on a
+// specified site name.  LEDModeDefault uses the site's LED setting.
+func (c *Client) SetDeviceLED(siteName string, deviceID string, mode LEDMode) error {
+	return c.updateDevice(siteName, deviceID, func(d map[string]interface{}) error {
+		d["led_override"] = mode.String()
+		return nil
+	})
+}
+
+// ledConcurrency is the maximum number of Devices updated concurrently by
+// Client.SetAllDeviceLEDs.
+const ledConcurrency = 4
+
+// SetAllDeviceLEDs sets the LED mode of all of the connected Devices on a
+// specified site name.  Devices which are not connected are skipped.
+//
+// Devices are updated concurrently.  Every Device is updated even if updating
+// another fails; the returned error joins the errors for each Device which
+// could not be updated.
+func (c *Client) SetAllDeviceLEDs(siteName string, mode LEDMode) error {
+	devices, err := c.Devices(siteName)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, ledConcurrency)
+	)
+
+	for _, d := range devices {
+		if d.State != StateConnected {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d *Device) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := c.SetDeviceLED(siteName, d.ID, mode); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, fmt.Errorf("failed to set LED mode for device %q: %w", deviceName(d), err))
+			}
+		}(d)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// deviceName returns a human-readable identifier for a Device.
+func deviceName(d *Device) string {
+	switch {
+	case d.Name != "":
+		return d.Name
+	case d.MAC != nil:
+		return d.MAC.String()
+	default:
+		return d.ID
+	}
+}
+
+// updateDevice performs a read-modify-write of the raw configuration of the
+// device with the specified ID on a site.  fn is called to modify the raw
+// configuration, and only the top-level fields it changes are written back,
+// so that concurrent changes to other fields are not overwritten.  If the
+// device does not exist, ErrNotFound is returned.
+func (c *Client) updateDevice(siteName string, deviceID string, fn func(d map[string]interface{}) error) error {
+	raw, err := getOne[json.RawMessage](context.Background(), c, siteName, "rest/device/"+deviceID)
+	if err != nil {
+		return err
+	}
+
+	// Decode the configuration twice, so that fn may modify nested values
+	// in place without affecting the original used for comparison.
+	var orig, d map[string]interface{}
+	if err := json.Unmarshal(*raw, &orig); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(*raw, &d); err != nil {
+		return err
+	}
+
+	if err := fn(d); err != nil {
+		return err
+	}
+
+	changed := make(map[string]interface{})
+	for k, v := range d {
+		if ov, ok := orig[k]; !ok || !reflect.DeepEqual(ov, v) {
+			changed[k] = v
+		}
 	}
 
-	_, err = c.do(req, &v)
-	return v.Devices, err
+	if len(changed) == 0 {
+		return nil
+	}
+
+	req, err := c.newRequest(
+		http.MethodPut,
+		fmt.Sprintf("/api/s/%s/rest/device/%s", siteName, deviceID),
+		changed,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
 }
 
 // A Device is a Ubiquiti UniFi device, such as a UniFi access point.
 type Device struct {
-	ID        string
-	Adopted   bool
-	InformIP  net.IP
-	InformURL *url.URL
-	Model     string
-	Name      string
-	NICs      []*NIC
-	Radios    []*Radio
-	Serial    string
-	SiteID    string
-	Stats     *DeviceStats
-	Uptime    time.Duration
-	Version   string
+	ID          string
+	Adopted     bool
+	InformIP    net.IP
+	InformURL   *url.URL
+	MAC         net.HardwareAddr
+	Model       string
+	Name        string
+	NICs        []*NIC
+	PowerUsage  float64 // Power draw in watts, if reported
+	Radios      []*Radio
+	Serial      string
+	SiteID      string
+	StartupTime time.Time
+	Stats       *DeviceStats
+	Type        DeviceType
+	Uptime      time.Duration
+	Version     string
+
+	// TwoPhaseAdopt reports whether the Device is being adopted using the
+	// two-phase process, in which it first connects to the UniFi Controller
+	// and then waits to be reconfigured with its permanent inform URL.
+	TwoPhaseAdopt bool
+
+	// Overheating and FanLevel report the thermal state of Devices with
+	// hardware monitoring, such as switches and gateways.  Devices which do
+	// not report them are treated as not overheating, with no fan.
+	Overheating bool
+	FanLevel    int
+
+	// Scanning reports whether the Device is performing an RF scan, during
+	// which its Stations may experience degraded service.
+	Scanning bool
+
+	// ConnectRequestIP is the source IP address from which the Device last
+	// contacted the UniFi Controller, which may differ from InformIP when the
+	// Device is behind NAT.  It is nil if the Device does not report it.
+	ConnectRequestIP net.IP
+
+	// BoardRev is the revision of the Device's hardware, and HWCaps is the
+	// undecoded bitfield describing the capabilities of that hardware.
+	BoardRev int
+	HWCaps   HWCaps
+
+	// VAPs contains the virtual access points broadcast by an access
+	// point's Radios, one for each WLAN on each Radio.
+	VAPs []*VAP
+
+	// Uplink describes the link by which the Device connects to the rest of
+	// the network.  It is nil if the Device does not report an uplink.
+	Uplink *Uplink
+
+	// IP is the Device's current IP address, which may differ from InformIP
+	// if the Device has been reconfigured.  It is nil if the Device does not
+	// report a valid address.
+	IP net.IP
+
+	// LastSeen is the time at which the Device last contacted the UniFi
+	// Controller.  It is the zero time if the Device has never been seen.
+	LastSeen time.Time
+
+	// State is the connection state reported by the UniFi Controller.
+	State DeviceState
+
+	// Ports contains the switch ports of a Device, in the order reported by
+	// the UniFi Controller.  It is empty for Devices which are not switches.
+	Ports []*Port
 
 	// TODO(mdlayher): add more fields from unexported device type
 }
 
+// HWCaps is a bitfield which describes the capabilities of a Device's
+// hardware, exactly as reported by the UniFi Controller.  The meaning of its
+// bits is not documented by Ubiquiti, so it is not decoded further; use
+// Device.SupportsPoE and Device.Supports5GHz, which are derived from the
+// Device's ports and radios, instead.
+type HWCaps uint32
+
+// SupportsPoE reports whether any of the Device's switch Ports can supply PoE
+// to connected devices.
+func (d *Device) SupportsPoE() bool {
+	for _, p := range d.Ports {
+		if p.PoECapable {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Supports5GHz reports whether the Device has a 5GHz Radio.
+func (d *Device) Supports5GHz() bool {
+	for _, r := range d.Radios {
+		if r.Radio == radio5GHz {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A DeviceState is the connection state of a Device, as reported by the UniFi
+// Controller.
+type DeviceState int
+
+// List of possible DeviceState values.  The values match those used by the
+// UniFi Controller API.
+const (
+	StateDisconnected    DeviceState = 0
+	StateConnected       DeviceState = 1
+	StatePending         DeviceState = 2
+	StateUpgrading       DeviceState = 4
+	StateProvisioning    DeviceState = 5
+	StateHeartbeatMissed DeviceState = 6
+	StateAdopting        DeviceState = 7
+	StateDeleting        DeviceState = 8
+	StateInformError     DeviceState = 9
+	StateAdoptFailed     DeviceState = 10
+	StateIsolated        DeviceState = 11
+)
+
+// String returns the string representation of a DeviceState.
+func (s DeviceState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnected:
+		return "connected"
+	case StatePending:
+		return "pending"
+	case StateUpgrading:
+		return "upgrading"
+	case StateProvisioning:
+		return "provisioning"
+	case StateHeartbeatMissed:
+		return "heartbeat missed"
+	case StateAdopting:
+		return "adopting"
+	case StateDeleting:
+		return "deleting"
+	case StateInformError:
+		return "inform error"
+	case StateAdoptFailed:
+		return "adoption failed"
+	case StateIsolated:
+		return "isolated"
+	default:
+		return fmt.Sprintf("DeviceState(%d)", s)
+	}
+}
+
+// An AdoptionState is the stage a Device has reached in the adoption process.
+type AdoptionState int
+
+// List of possible AdoptionState values.
+const (
+	AdoptionUnknown AdoptionState = iota
+	AdoptionPending
+	AdoptionAdopting
+	AdoptionProvisioning
+	AdoptionAdopted
+	AdoptionFailed
+)
+
+// String returns the string representation of an AdoptionState.
+func (s AdoptionState) String() string {
+	switch s {
+	case AdoptionPending:
+		return "pending"
+	case AdoptionAdopting:
+		return "adopting"
+	case AdoptionProvisioning:
+		return "provisioning"
+	case AdoptionAdopted:
+		return "adopted"
+	case AdoptionFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// AdoptionState returns the stage the Device has reached in the adoption
+// process, derived from its state and adoption flags.  Combinations which
+// cannot be classified are reported as AdoptionUnknown.
+func (d *Device) AdoptionState() AdoptionState {
+	// These states take precedence over the adopted flag, which may be set
+	// before adoption has fully completed.
+	switch d.State {
+	case StateAdoptFailed:
+		return AdoptionFailed
+	case StateAdopting:
+		return AdoptionAdopting
+	case StateProvisioning:
+		return AdoptionProvisioning
+	case StatePending:
+		return AdoptionPending
+	}
+
+	switch {
+	case d.TwoPhaseAdopt:
+		return AdoptionAdopting
+	case d.Adopted:
+		return AdoptionAdopted
+	default:
+		return AdoptionUnknown
+	}
+}
+
+// A DeviceType is the class of a Device, such as an access point or switch.
+type DeviceType int
+
+// List of possible DeviceType values.
+const (
+	DeviceTypeUnknown DeviceType = iota
+	DeviceTypeAccessPoint
+	DeviceTypeSwitch
+	DeviceTypeGateway
+)
+
+// String returns the string representation of a DeviceType.
+func (t DeviceType) String() string {
+	switch t {
+	case DeviceTypeAccessPoint:
+		return "access point"
+	case DeviceTypeSwitch:
+		return "switch"
+	case DeviceTypeGateway:
+		return "gateway"
+	default:
+		return "unknown"
+	}
+}
+
+// parseDeviceType parses a DeviceType from the raw type string returned by
+// the UniFi Controller.
+func parseDeviceType(s string) DeviceType {
+	switch s {
+	case "uap":
+		return DeviceTypeAccessPoint
+	case "usw":
+		return DeviceTypeSwitch
+	// UniFi Dream Machines combine a gateway, switch, and access point, but
+	// are primarily managed as the gateway for a site.
+	case "ugw", "udm", "uxg":
+		return DeviceTypeGateway
+	default:
+		return DeviceTypeUnknown
+	}
+}
+
+// ClockDrift estimates how far the Device's clock has drifted from now, the
+// current time according to the UniFi Controller, and reports whether the
+// magnitude of that drift exceeds threshold.  A positive drift indicates that
+// the Device's clock is ahead.
+//
+// The estimate compares the Device's self-reported StartupTime against the
+// boot time implied by its Uptime.  Because Uptime is only as fresh as the
+// Device's most recent report to the controller, the estimate may be off by up
+// to the Device's inform interval, and thresholds should be chosen with that
+// in mind.  If the Device did not report a StartupTime, ClockDrift returns
+// zero and false.
+func (d *Device) ClockDrift(now time.Time, threshold time.Duration) (time.Duration, bool) {
+	if d.StartupTime.IsZero() {
+		return 0, false
+	}
+
+	drift := d.StartupTime.Sub(now.Add(-d.Uptime))
+	if drift < 0 {
+		return drift, -drift > threshold
+	}
+
+	return drift, drift > threshold
+}
+
+// TotalStations returns the number of Stations connected to all of the
+// Device's Radios.  Devices without Radios, such as switches and gateways,
+// report zero.
+func (d *Device) TotalStations() int {
+	return d.sumStations(func(s *RadioStationsStats) int { return s.NumberStations })
+}
+
+// GuestStations returns the number of guest Stations connected to all of the
+// Device's Radios.
+func (d *Device) GuestStations() int {
+	return d.sumStations(func(s *RadioStationsStats) int { return s.NumberGuestStations })
+}
+
+// UserStations returns the number of user Stations connected to all of the
+// Device's Radios.
+func (d *Device) UserStations() int {
+	return d.sumStations(func(s *RadioStationsStats) int { return s.NumberUserStations })
+}
+
+// sumStations sums a Station count selected by fn across all Radios.
+func (d *Device) sumStations(fn func(s *RadioStationsStats) int) int {
+	var n int
+	for _, r := range d.Radios {
+		if r.Stats == nil {
+			continue
+		}
+
+		n += fn(r.Stats)
+	}
+
+	return n
+}
+
 // A Radio is a wireless radio, attached to a Device.
 type Radio struct {
+	Airtime            *Airtime
 	BuiltInAntenna     bool
 	BuiltInAntennaGain int
+	ChannelWidth       int // Channel width in MHz
 	MaxTXPower         int
 	MinTXPower         int
 	Name               string
 	Radio              string
 	Stats              *RadioStationsStats
+
+	// AllowedChannels contains the channels the Radio may use.  It is nil
+	// if the UniFi Controller does not report the Radio's channels, so that
+	// unknown channels can be distinguished from none.
+	AllowedChannels []int
+
+	// DFS reports whether the Radio supports DFS channels.
+	DFS bool
 }
 
 // RadioStationsStats contains Station statistics for a Radio.
@@ -64,19 +987,117 @@ type RadioStationsStats struct {
 	NumberUserStations  int
 }
 
+// Airtime contains the channel utilization of a Radio, as percentages of
+// airtime.  Total includes airtime used by nearby devices, so a large
+// difference between Total and the Radio's own usage indicates interference
+// or congestion from other networks.
+type Airtime struct {
+	SelfReceive  int
+	SelfTransmit int
+	Total        int
+}
+
 // A NIC is a wired ethernet network interface, attached to a Device.
 type NIC struct {
 	MAC  net.HardwareAddr
 	Name string
 }
 
+// RandomizedMAC reports whether the NIC's MAC address is locally
+// administered rather than assigned by its manufacturer.
+func (n *NIC) RandomizedMAC() bool { return locallyAdministered(n.MAC) }
+
+// An Uplink is the link by which a Device connects to the rest of the
+// network.  Speed and MaxSpeed are in Mbps, and are zero if not reported.
+// FullDuplex is nil if the Uplink does not report its duplex.
+type Uplink struct {
+	Type       string
+	Speed      int
+	MaxSpeed   int
+	FullDuplex *bool
+}
+
+// Degraded reports whether a wired Uplink has negotiated a lower speed than
+// it is capable of, or half duplex, which often indicates a faulty cable.
+// Wireless Uplinks and Uplinks which do not report a speed are never
+// degraded, and only the values an Uplink reports are checked: an unknown
+// MaxSpeed or duplex is not considered degraded.
+func (u *Uplink) Degraded() bool {
+	if u.Type == "wireless" || u.Speed == 0 {
+		return false
+	}
+
+	if u.MaxSpeed > 0 && u.Speed < u.MaxSpeed {
+		return true
+	}
+
+	return u.FullDuplex != nil && !*u.FullDuplex
+}
+
+// A Port is a switch port on a Device.  Speed is in Mbps, and PoEPower is
+// the power currently drawn by a powered device, in watts.
+type Port struct {
+	PortIndex  int
+	Name       string
+	Up         bool
+	Speed      int
+	FullDuplex bool
+
+	// PoECapable reports whether the Port can supply PoE, and PoEEnabled
+	// whether it is currently configured to do so.
+	PoECapable bool
+	PoEEnabled bool
+	PoEMode    string
+	PoEPower   float64
+
+	ReceiveBytes  uint64
+	TransmitBytes uint64
+}
+
+// TotalPoEPower returns the total power in watts drawn by powered devices
+// from the Device's switch Ports.  A PoE switch reports the same total in
+// PowerUsage, so the two may be compared to cross-check the Device's
+// reporting.  It is zero for Devices with no Ports.
+func (d *Device) TotalPoEPower() float64 {
+	var total float64
+	for _, p := range d.Ports {
+		total += p.PoEPower
+	}
+
+	return total
+}
+
+// A VAP is a virtual access point: a WLAN broadcast by one of an access
+// point's Radios.
+type VAP struct {
+	BSSID    net.HardwareAddr
+	ESSID    string
+	Radio    string
+	Stations int
+}
+
 // DeviceStats contains device network activity statistics.
+//
+// Byte counters are provided both as float64 values, for compatibility, and
+// as exact integers.  A float64 cannot exactly represent a counter larger
+// than 2^53, so the integer fields should be preferred for accounting.
 type DeviceStats struct {
-	TotalBytes float64
-	All        *WirelessStats
-	Guest      *WirelessStats
-	User       *WirelessStats
-	Uplink     *WiredStats
+	TotalBytes      float64
+	TotalBytesExact uint64
+	All             *WirelessStats
+	Guest           *WirelessStats
+	User            *WirelessStats
+	Uplink          *WiredStats
+
+	// UplinkLatency is the latency of a gateway's uplink, as measured by the
+	// gateway for the UniFi Controller's health dashboard.  It is zero for
+	// other Devices, or if the gateway has not measured its latency.
+	UplinkLatency time.Duration
+
+	// WANs contains statistics for each WAN interface of a gateway, in
+	// order, beginning with the primary WAN.  Single-WAN gateways report
+	// one element, and other Devices report none.
+	WANs []*WANStats
 }
 
 func (s *DeviceStats) String() string {
@@ -84,24 +1105,74 @@ func (s *DeviceStats) String() string {
 }
 
 // WirelessStats contains wireless device network activity statistics.
+//
+// See DeviceStats for the difference between the float64 and exact integer
+// byte counters.
 type WirelessStats struct {
-	ReceiveBytes    float64
-	ReceivePackets  float64
-	TransmitBytes   float64
-	TransmitDropped float64
-	TransmitPackets float64
+	ReceiveBytes       float64
+	ReceiveBytesExact  uint64
+	ReceivePackets     float64
+	TransmitBytes      float64
+	TransmitBytesExact uint64
+	TransmitDropped    float64
+	TransmitPackets    float64
 }
 
 func (s *WirelessStats) String() string {
 	return fmt.Sprintf("%v", *s)
 }
 
+// WANStats contains gateway WAN interface network activity statistics.
+//
+// See DeviceStats for the difference between the float64 and exact integer
+// byte counters.
+type WANStats struct {
+	Name               string // Logical WAN name, such as "wan1"
+	Interface          string // Physical interface name, such as "eth0"
+	Up                 bool
+	ReceiveBytes       float64
+	ReceiveBytesExact  uint64
+	ReceivePackets     float64
+	TransmitBytes      float64
+	TransmitBytesExact uint64
+	TransmitPackets    float64
+}
+
+func (s *WANStats) String() string {
+	return fmt.Sprintf("%v", *s)
+}
+
+// newWANStats creates WANStats for the named WAN from its raw interface.  A
+// nil interface produces WANStats with only a name.
+func newWANStats(name string, w *wanInterface) *WANStats {
+	if w == nil {
+		return &WANStats{Name: name}
+	}
+
+	return &WANStats{
+		Name:               name,
+		Interface:          w.Name,
+		Up:                 w.Up,
+		ReceiveBytes:       numberFloat(w.RxBytes),
+		ReceiveBytesExact:  numberUint(w.RxBytes),
+		ReceivePackets:     w.RxPackets,
+		TransmitBytes:      numberFloat(w.TxBytes),
+		TransmitBytesExact: numberUint(w.TxBytes),
+		TransmitPackets:    w.TxPackets,
+	}
+}
+
 // WiredStats contains wired device network activity statistics.
+//
+// See DeviceStats for the difference between the float64 and exact integer
+// byte counters.
 type WiredStats struct {
-	ReceiveBytes    float64
-	ReceivePackets  float64
-	TransmitBytes   float64
-	TransmitPackets float64
+	ReceiveBytes       float64
+	ReceiveBytesExact  uint64
+	ReceivePackets     float64
+	TransmitBytes      float64
+	TransmitBytesExact uint64
+	TransmitPackets    float64
 }
 
 func (s *WiredStats) String() string {
@@ -116,6 +1187,10 @@ const (
 	radio24GHz = "2.4GHz"
 )
 
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a Device is unmarshaled.
+func (*Device) raw() interface{} { return new(device) }
+
 // UnmarshalJSON unmarshals the raw JSON representation of a Device.
 func (d *Device) UnmarshalJSON(b []byte) error {
 	var dev device
@@ -133,6 +1208,15 @@ func (d *Device) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	// A device which has not yet been fully adopted may not report a MAC.
+	var mac net.HardwareAddr
+	if dev.MAC != "" {
+		mac, err = net.ParseMAC(dev.MAC)
+		if err != nil {
+			return err
+		}
+	}
+
 	nics := make([]*NIC, 0, len(dev.EthernetTable))
 	for _, et := range dev.EthernetTable {
 		mac, err := net.ParseMAC(et.MAC)
@@ -146,11 +1230,74 @@ func (d *Device) UnmarshalJSON(b []byte) error {
 		})
 	}
 
+	var vaps []*VAP
+	for _, vt := range dev.VAPTable {
+		var bssid net.HardwareAddr
+		if vt.BSSID != "" {
+			bssid, err = net.ParseMAC(vt.BSSID)
+			if err != nil {
+				return err
+			}
+		}
+
+		v := &VAP{
+			BSSID:    bssid,
+			ESSID:    vt.ESSID,
+			Stations: vt.NumSta,
+		}
+
+		switch vt.Radio {
+		case radioNA:
+			v.Radio = radio5GHz
+		case radioNG:
+			v.Radio = radio24GHz
+		}
+
+		vaps = append(vaps, v)
+	}
+
+	var ports []*Port
+	for _, pt := range dev.PortTable {
+		ports = append(ports, &Port{
+			PortIndex:  pt.PortIdx,
+			Name:       pt.Name,
+			Up:         pt.Up,
+			Speed:      pt.Speed,
+			FullDuplex: pt.FullDuplex,
+
+			PoECapable: pt.PortPoE,
+			PoEEnabled: pt.PoEEnable,
+			PoEMode:    pt.PoEMode,
+			PoEPower:   numberFloat(pt.PoEPower),
+
+			ReceiveBytes:  numberUint(pt.RxBytes),
+			TransmitBytes: numberUint(pt.TxBytes),
+		})
+	}
+
 	radios := make([]*Radio, 0, len(dev.RadioTable))
 	for _, rt := range dev.RadioTable {
+		// Radios which do not report a channel width use the 20MHz
+		// minimum.
+		width := int(rt.HT)
+		if width == 0 {
+			width = 20
+		}
+
+		var channels []int
+		if rt.Channels != nil {
+			channels = make([]int, 0, len(rt.Channels))
+			for _, ch := range rt.Channels {
+				channels = append(channels, int(ch))
+			}
+		}
+
 		r := &Radio{
+			AllowedChannels:    channels,
 			BuiltInAntenna:     rt.BuiltinAntenna,
 			BuiltInAntennaGain: rt.BuiltinAntGain,
+			ChannelWidth:       width,
+			DFS:                rt.HasDFS,
 			MaxTXPower:         rt.MaxTXPower,
 			MinTXPower:         rt.MinTXPower,
 			Name:               rt.Name,
@@ -163,6 +1310,11 @@ func (d *Device) UnmarshalJSON(b []byte) error {
 					NumberUserStations:  v.UserNumSta,
 					NumberGuestStations: v.GuestNumSta,
 				}
+				r.Airtime = &Airtime{
+					SelfReceive:  v.CuSelfRx,
+					SelfTransmit: v.CuSelfTx,
+					Total:        v.CuTotal,
+				}
 			}
 		}
 
@@ -176,48 +1328,132 @@ func (d *Device) UnmarshalJSON(b []byte) error {
 		radios = append(radios, r)
 	}
 
+	// PoE switches report the total power supplied to their ports, while
+	// some other devices report only their own draw.
+	power := dev.TotalUsedPower
+	if power == "" {
+		power = dev.Power
+	}
+
+	typ := parseDeviceType(dev.Type)
+
+	// Gateways always report their primary WAN, and report a secondary WAN
+	// only when one is configured.
+	var wans []*WANStats
+	if typ == DeviceTypeGateway {
+		wans = append(wans, newWANStats("wan1", dev.WAN1))
+		if dev.WAN2 != nil {
+			wans = append(wans, newWANStats("wan2", dev.WAN2))
+		}
+	}
+
+	var latency time.Duration
+	if typ == DeviceTypeGateway {
+		latency = time.Duration(dev.Uplink.Latency * float64(time.Millisecond))
+	}
+
+	var uplink *Uplink
+	if dev.Uplink.Type != "" {
+		uplink = &Uplink{
+			Type:       dev.Uplink.Type,
+			Speed:      dev.Uplink.Speed,
+			MaxSpeed:   dev.Uplink.MaxSpeed,
+			FullDuplex: dev.Uplink.FullDuplex,
+		}
+	}
+
+	var lastSeen time.Time
+	if dev.LastSeen != 0 {
+		lastSeen = time.Unix(int64(dev.LastSeen), 0)
+	}
+
+	var startup time.Time
+	if dev.StartupTimestamp != 0 {
+		startup = time.Unix(dev.StartupTimestamp, 0)
+	}
+
 	*d = Device{
-		ID:        dev.ID,
-		Adopted:   dev.Adopted,
-		InformIP:  informIP,
-		InformURL: informURL,
-		Model:     dev.Model,
-		Name:      dev.Name,
-		NICs:      nics,
-		Radios:    radios,
-		Serial:    dev.Serial,
-		SiteID:    dev.SiteID,
-		Uptime:    time.Duration(time.Duration(dev.Uptime) * time.Second),
-		Version:   dev.Version,
+		ID:          dev.ID,
+		Adopted:     dev.Adopted,
+		InformIP:    informIP,
+		InformURL:   informURL,
+		MAC:         mac,
+		Model:       dev.Model,
+		Name:        dev.Name,
+		NICs:        nics,
+		PowerUsage:  numberFloat(power),
+		Radios:      radios,
+		Serial:      dev.Serial,
+		SiteID:      dev.SiteID,
+		StartupTime: startup,
+		Type:        typ,
+		Uptime:      time.Duration(time.Duration(dev.Uptime) * time.Second),
+		Version:     dev.Version,
+
+		TwoPhaseAdopt: dev.TwoPhaseAdopt,
+
+		Overheating: dev.Overheating,
+		FanLevel:    dev.FanLevel,
+
+		Scanning: dev.Scanning || dev.SpectrumScanning,
+
+		ConnectRequestIP: net.ParseIP(dev.ConnectRequestIP),
+
+		BoardRev: dev.BoardRev,
+		HWCaps:   HWCaps(dev.HWCaps),
+
+		VAPs: vaps,
+
+		Uplink: uplink,
+
+		// A Device which has not finished provisioning may report an empty
+		// IP address.
+		IP:       net.ParseIP(dev.IP),
+		LastSeen: lastSeen,
+		State:    dev.State,
+
+		Ports: ports,
+
 		Stats: &DeviceStats{
-			TotalBytes: dev.Stat.Bytes,
+			TotalBytes:      numberFloat(dev.Stat.Bytes),
+			TotalBytesExact: numberUint(dev.Stat.Bytes),
 			All: &WirelessStats{
-				ReceiveBytes:    dev.Stat.RxBytes,
-				ReceivePackets:  dev.Stat.RxPackets,
-				TransmitBytes:   dev.Stat.TxBytes,
-				TransmitDropped: dev.Stat.TxDropped,
-				TransmitPackets: dev.Stat.TxPackets,
+				ReceiveBytes:       numberFloat(dev.Stat.RxBytes),
+				ReceiveBytesExact:  numberUint(dev.Stat.RxBytes),
+				ReceivePackets:     dev.Stat.RxPackets,
+				TransmitBytes:      numberFloat(dev.Stat.TxBytes),
+				TransmitBytesExact: numberUint(dev.Stat.TxBytes),
+				TransmitDropped:    dev.Stat.TxDropped,
+				TransmitPackets:    dev.Stat.TxPackets,
 			},
 			User: &WirelessStats{
-				ReceiveBytes:    dev.Stat.UserRxBytes,
-				ReceivePackets:  dev.Stat.UserRxPackets,
-				TransmitBytes:   dev.Stat.UserTxBytes,
-				TransmitDropped: dev.Stat.UserTxDropped,
-				TransmitPackets: dev.Stat.UserTxPackets,
+				ReceiveBytes:       numberFloat(dev.Stat.UserRxBytes),
+				ReceiveBytesExact:  numberUint(dev.Stat.UserRxBytes),
+				ReceivePackets:     dev.Stat.UserRxPackets,
+				TransmitBytes:      numberFloat(dev.Stat.UserTxBytes),
+				TransmitBytesExact: numberUint(dev.Stat.UserTxBytes),
+				TransmitDropped:    dev.Stat.UserTxDropped,
+				TransmitPackets:    dev.Stat.UserTxPackets,
 			},
 			Guest: &WirelessStats{
-				ReceiveBytes:    dev.Stat.GuestRxBytes,
-				ReceivePackets:  dev.Stat.GuestRxPackets,
-				TransmitBytes:   dev.Stat.GuestTxBytes,
-				TransmitDropped: dev.Stat.GuestTxDropped,
-				TransmitPackets: dev.Stat.GuestTxPackets,
+				ReceiveBytes:       numberFloat(dev.Stat.GuestRxBytes),
+				ReceiveBytesExact:  numberUint(dev.Stat.GuestRxBytes),
+				ReceivePackets:     dev.Stat.GuestRxPackets,
+				TransmitBytes:      numberFloat(dev.Stat.GuestTxBytes),
+				TransmitBytesExact: numberUint(dev.Stat.GuestTxBytes),
+				TransmitDropped:    dev.Stat.GuestTxDropped,
+				TransmitPackets:    dev.Stat.GuestTxPackets,
 			},
 			Uplink: &WiredStats{
-				ReceiveBytes:    dev.Uplink.RxBytes,
-				ReceivePackets:  dev.Uplink.RxPackets,
-				TransmitBytes:   dev.Uplink.TxBytes,
-				TransmitPackets: dev.Uplink.TxPackets,
+				ReceiveBytes:       numberFloat(dev.Uplink.RxBytes),
+				ReceiveBytesExact:  numberUint(dev.Uplink.RxBytes),
+				ReceivePackets:     dev.Uplink.RxPackets,
+				TransmitBytes:      numberFloat(dev.Uplink.TxBytes),
+				TransmitBytesExact: numberUint(dev.Uplink.TxBytes),
+				TransmitPackets:    dev.Uplink.TxPackets,
 			},
+			UplinkLatency: latency,
+			WANs:          wans,
 		},
 	}
 
@@ -230,20 +1466,24 @@ type device struct {
 	// TODO(mdlayher): give all fields appropriate names and data types.
 	ID            string  `json:"_id"`
 	Adopted       bool    `json:"adopted"`
+	BoardRev      int     `json:"board_rev"`
 	Bytes         float64 `json:"bytes"`
 	ConfigVersion string  `json:"cfgversion"`
 	ConfigNetwork struct {
 		IP   string `json:"ip"`
 		Type string `json:"type"`
 	} `json:"config_network"`
-	DeviceID      string `json:"device_id"`
-	EthernetTable []struct {
+	ConnectRequestIP string `json:"connect_request_ip"`
+	DeviceID         string `json:"device_id"`
+	EthernetTable    []struct {
 		MAC     string `json:"mac"`
 		Name    string `json:"name"`
 		NumPort int    `json:"num_port"`
 	} `json:"ethernet_table"`
+	FanLevel    int    `json:"fan_level"`
 	GuestNumSta int    `json:"guest-num_sta"`
 	HasSpeaker  bool   `json:"has_speaker"`
+	HWCaps      uint32 `json:"hw_caps"`
 	InformIP    string `json:"inform_ip"`
 	InformURL   string `json:"inform_url"`
 	IP          string `json:"ip"`
@@ -252,7 +1492,22 @@ type device struct {
 	Model       string `json:"model"`
 	Name        string `json:"name"`
 	NumSta      int    `json:"num_sta"`
-	RadioNg     struct {
+	Overheating bool   `json:"overheating"`
+	PortTable   []struct {
+		FullDuplex bool        `json:"full_duplex"`
+		Name       string      `json:"name"`
+		PoEEnable  bool        `json:"poe_enable"`
+		PoEMode    string      `json:"poe_mode"`
+		PoEPower   json.Number `json:"poe_power"`
+		PortIdx    int         `json:"port_idx"`
+		PortPoE    bool        `json:"port_poe"`
+		RxBytes    json.Number `json:"rx_bytes"`
+		Speed      int         `json:"speed"`
+		TxBytes    json.Number `json:"tx_bytes"`
+		Up         bool        `json:"up"`
+	} `json:"port_table"`
+	Power   json.Number `json:"power"`
+	RadioNg struct {
 		BuiltInAntennaGain int    `json:"builtin_ant_gain"`
 		BuiltInAntenna     bool   `json:"builtin_antenna"`
 		MaxTXPower         int    `json:"max_txpower"`
@@ -261,12 +1516,15 @@ type device struct {
 		Radio              string `json:"radio"`
 	} `json:"radio_ng"`
 	RadioTable []struct {
-		BuiltinAntGain int    `json:"builtin_ant_gain"`
-		BuiltinAntenna bool   `json:"builtin_antenna"`
-		MaxTXPower     int    `json:"max_txpower"`
-		MinTXPower     int    `json:"min_txpower"`
-		Name           string `json:"name"`
-		Radio          string `json:"radio"`
+		BuiltinAntGain int       `json:"builtin_ant_gain"`
+		BuiltinAntenna bool      `json:"builtin_antenna"`
+		Channels       []flexInt `json:"channels"`
+		HasDFS         bool      `json:"has_dfs"`
+		HT             flexInt   `json:"ht"`
+		MaxTXPower     int       `json:"max_txpower"`
+		MinTXPower     int       `json:"min_txpower"`
+		Name           string    `json:"name"`
+		Radio          string    `json:"radio"`
 	} `json:"radio_table"`
 	RadioTableStats []struct {
 		AstBeXmit   int         `json:"ast_be_xmit"`
@@ -288,48 +1546,119 @@ type device struct {
 		TxRetries   int         `json:"tx_retries"`
 		UserNumSta  int         `json:"user-num_sta"`
 	} `json:"radio_table_stats"`
-	RxBytes float64 `json:"rx_bytes"`
-	Serial  string  `json:"serial,omitempty"`
-	SiteID  string  `json:"site_id"`
-	Stat    struct {
-		Bytes          float64 `json:"bytes"`
-		GuestRxBytes   float64 `json:"guest-rx_bytes"`
-		GuestRxPackets float64 `json:"guest-rx_packets"`
-		GuestTxBytes   float64 `json:"guest-tx_bytes"`
-		GuestTxDropped float64 `json:"guest-tx_dropped"`
-		GuestTxPackets float64 `json:"guest-tx_packets"`
-		Mac            string  `json:"mac"`
-		RxBytes        float64 `json:"rx_bytes"`
-		RxPackets      float64 `json:"rx_packets"`
-		TxBytes        float64 `json:"tx_bytes"`
-		TxDropped      float64 `json:"tx_dropped"`
-		TxPackets      float64 `json:"tx_packets"`
-		UserRxBytes    float64 `json:"user-rx_bytes"`
-		UserRxPackets  float64 `json:"user-rx_packets"`
-		UserTxBytes    float64 `json:"user-tx_bytes"`
-		UserTxDropped  float64 `json:"user-tx_dropped"`
-		UserTxPackets  float64 `json:"user-tx_packets"`
+	RxBytes          float64 `json:"rx_bytes"`
+	Scanning         bool    `json:"scanning"`
+	Serial           string  `json:"serial,omitempty"`
+	SiteID           string  `json:"site_id"`
+	SpectrumScanning bool    `json:"spectrum_scanning"`
+	Stat             struct {
+		Bytes          json.Number `json:"bytes"`
+		GuestRxBytes   json.Number `json:"guest-rx_bytes"`
+		GuestRxPackets float64     `json:"guest-rx_packets"`
+		GuestTxBytes   json.Number `json:"guest-tx_bytes"`
+		GuestTxDropped float64     `json:"guest-tx_dropped"`
+		GuestTxPackets float64     `json:"guest-tx_packets"`
+		Mac            string      `json:"mac"`
+		RxBytes        json.Number `json:"rx_bytes"`
+		RxPackets      float64     `json:"rx_packets"`
+		TxBytes        json.Number `json:"tx_bytes"`
+		TxDropped      float64     `json:"tx_dropped"`
+		TxPackets      float64     `json:"tx_packets"`
+		UserRxBytes    json.Number `json:"user-rx_bytes"`
+		UserRxPackets  float64     `json:"user-rx_packets"`
+		UserTxBytes    json.Number `json:"user-tx_bytes"`
+		UserTxDropped  float64     `json:"user-tx_dropped"`
+		UserTxPackets  float64     `json:"user-tx_packets"`
 	} `json:"stat"`
 	Uplink struct {
-		RxBytes   float64 `json:"rx_bytes"`
-		RxPackets float64 `json:"rx_packets"`
-		RxErrors  float64 `json:"rx_errors"`
-		TxBytes   float64 `json:"tx_bytes"`
-		TxPackets float64 `json:"tx_packets"`
-		TxErrors  float64 `json:"tx_errors"`
-		Type      string  `json:"type"`
+		FullDuplex *bool       `json:"full_duplex"`
+		Latency    float64     `json:"latency"`
+		MaxSpeed   int         `json:"max_speed"`
+		RxBytes    json.Number `json:"rx_bytes"`
+		RxPackets  float64     `json:"rx_packets"`
+		RxErrors   float64     `json:"rx_errors"`
+		Speed      int         `json:"speed"`
+		TxBytes    json.Number `json:"tx_bytes"`
+		TxPackets  float64     `json:"tx_packets"`
+		TxErrors   float64     `json:"tx_errors"`
+		Type       string      `json:"type"`
 	} `json:"uplink"`
-	State         int           `json:"state"`
-	TxBytes       float64       `json:"tx_bytes"`
-	Type          string        `json:"type"`
-	UplinkTable   []interface{} `json:"uplink_table"`
-	Uptime        int           `json:"uptime"`
-	UserNumSta    int           `json:"user-num_sta"`
+	StartupTimestamp int64         `json:"startup_timestamp"`
+	State            DeviceState   `json:"state"`
+	TotalUsedPower   json.Number   `json:"total_used_power"`
+	TxBytes          float64       `json:"tx_bytes"`
+	TwoPhaseAdopt    bool          `json:"two_phase_adopt"`
+	Type             string        `json:"type"`
+	UplinkTable      []interface{} `json:"uplink_table"`
+	Uptime           int           `json:"uptime"`
+	UserNumSta       int           `json:"user-num_sta"`
+	VAPTable         []struct {
+		BSSID  string `json:"bssid"`
+		ESSID  string `json:"essid"`
+		NumSta int    `json:"num_sta"`
+		Radio  string `json:"radio"`
+	} `json:"vap_table"`
 	Version       string        `json:"version"`
 	VwireEnabled  bool          `json:"vwireEnabled"`
 	VwireTable    []interface{} `json:"vwire_table"`
+	WAN1          *wanInterface `json:"wan1"`
+	WAN2          *wanInterface `json:"wan2"`
 	WlangroupIDNg string        `json:"wlangroup_id_ng"`
 	XAuthkey      string        `json:"x_authkey"`
 	XFingerprint  string        `json:"x_fingerprint"`
 	XVwirekey     string        `json:"x_vwirekey"`
 }
+
+// A wanInterface is the raw structure of a gateway WAN interface.
+type wanInterface struct {
+	Name      string      `json:"name"`
+	Up        bool        `json:"up"`
+	RxBytes   json.Number `json:"rx_bytes"`
+	RxPackets float64     `json:"rx_packets"`
+	TxBytes   json.Number `json:"tx_bytes"`
+	TxPackets float64     `json:"tx_packets"`
+}
+
+// A flexInt is an integer which the UniFi Controller may encode as either a
+// JSON number or a JSON string.
+type flexInt int
+
+// UnmarshalJSON unmarshals a flexInt from a JSON number or string.
+func (i *flexInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+
+	*i = flexInt(v)
+	return nil
+}
+
+// numberFloat returns the value of a JSON number as a float64, or zero if the
+// number is absent or invalid.
+func numberFloat(n json.Number) float64 {
+	f, _ := n.Float64()
+	return f
+}
+
+// numberUint returns the exact value of a JSON number as a uint64.  Numbers
+// which are not encoded as integers are converted from their floating point
+// value, and absent or invalid numbers are zero.
+func numberUint(n json.Number) uint64 {
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u
+	}
+
+	f := numberFloat(n)
+	if f < 0 {
+		return 0
+	}
+
+	return uint64(f)
+}
@@ -2,13 +2,17 @@ package unifi
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -85,6 +89,8 @@ func errStr(err error) string {
 }
 
 func TestDeviceUnmarshalJSON(t *testing.T) {
+	fullDuplex := true
+
 	var tests = []struct {
 		desc string
 		b    []byte
@@ -106,6 +112,16 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 			b:    []byte(`{"inform_ip":"192.168.1.1","ethernet_table":[{"mac":"foo"}]}`),
 			err:  errors.New("invalid MAC address"),
 		},
+		{
+			desc: "invalid MAC",
+			b:    []byte(`{"inform_ip":"192.168.1.1","mac":"foo"}`),
+			err:  errors.New("invalid MAC address"),
+		},
+		{
+			desc: "invalid radio channel width",
+			b:    []byte(`{"inform_ip":"192.168.1.1","radio_table":[{"ht":"foo"}]}`),
+			err:  errors.New("invalid syntax"),
+		},
 		{
 			desc: "OK",
 			b: bytes.TrimSpace([]byte(`
@@ -114,8 +130,12 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 	"adopted": true,
 	"inform_ip": "192.168.1.1",
 	"inform_url": "http://192.168.1.1:8080/inform",
+	"ip": "192.168.1.2",
+	"last_seen": 1451606461,
+	"mac": "de:ad:be:ef:00:01",
 	"model": "uap1000",
 	"name": "AP",
+	"state": 1,
 	"ethernet_table": [
 		{
 			"mac": "de:ad:be:ef:de:ad",
@@ -137,10 +157,14 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 			"max_txpower": 10,
 			"min_txpower": 1,
 			"name": "wlan1",
-			"radio": "na"
+			"radio": "na",
+			"ht": "80"
 		}
 	],
 	"radio_table_stats": [{
+		"cu_self_rx": 5,
+		"cu_self_tx": 10,
+		"cu_total": 45,
 		"guest-num_sta": 1,
 		"name": "wlan0",
 		"num_sta": 3,
@@ -153,6 +177,8 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 	}],
 	"serial": "deadbeef0123456789",
 	"site_id": "default",
+	"startup_timestamp": 1451606400,
+	"type": "uap",
 	"stat": {
 		"guest-rx_bytes": 101,
 		"guest-rx_packets": 5,
@@ -208,6 +234,7 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 
 					return u
 				}(),
+				MAC:   net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01},
 				Model: "uap1000",
 				Name:  "AP",
 				NICs: []*NIC{{
@@ -216,8 +243,14 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 				}},
 				Radios: []*Radio{
 					{
+						Airtime: &Airtime{
+							SelfReceive:  5,
+							SelfTransmit: 10,
+							Total:        45,
+						},
 						BuiltInAntenna:     true,
 						BuiltInAntennaGain: 1,
+						ChannelWidth:       20,
 						MaxTXPower:         10,
 						MinTXPower:         1,
 						Name:               "wlan0",
@@ -231,6 +264,8 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 					{
 						BuiltInAntenna:     true,
 						BuiltInAntennaGain: 1,
+						Airtime:            &Airtime{},
+						ChannelWidth:       80,
 						MaxTXPower:         10,
 						MinTXPower:         1,
 						Name:               "wlan1",
@@ -242,40 +277,60 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 						},
 					},
 				},
-				Serial: "deadbeef0123456789",
-				SiteID: "default",
+				Serial:      "deadbeef0123456789",
+				SiteID:      "default",
+				StartupTime: time.Unix(1451606400, 0),
 				Stats: &DeviceStats{
-					TotalBytes: 100,
+					TotalBytes:      100,
+					TotalBytesExact: 100,
 					All: &WirelessStats{
-						ReceiveBytes:    80,
-						ReceivePackets:  4,
-						TransmitBytes:   20,
-						TransmitDropped: 1,
-						TransmitPackets: 1,
+						ReceiveBytes:       80,
+						ReceiveBytesExact:  80,
+						ReceivePackets:     4,
+						TransmitBytes:      20,
+						TransmitBytesExact: 20,
+						TransmitDropped:    1,
+						TransmitPackets:    1,
 					},
 					User: &WirelessStats{
-						ReceiveBytes:    80,
-						ReceivePackets:  4,
-						TransmitBytes:   20,
-						TransmitDropped: 1,
-						TransmitPackets: 1,
+						ReceiveBytes:       80,
+						ReceiveBytesExact:  80,
+						ReceivePackets:     4,
+						TransmitBytes:      20,
+						TransmitBytesExact: 20,
+						TransmitDropped:    1,
+						TransmitPackets:    1,
 					},
 					Uplink: &WiredStats{
-						ReceiveBytes:    81,
-						ReceivePackets:  5,
-						TransmitBytes:   21,
-						TransmitPackets: 2,
+						ReceiveBytes:       81,
+						ReceiveBytesExact:  81,
+						ReceivePackets:     5,
+						TransmitBytes:      21,
+						TransmitBytesExact: 21,
+						TransmitPackets:    2,
 					},
 					Guest: &WirelessStats{
-						ReceiveBytes:    101,
-						ReceivePackets:  5,
-						TransmitBytes:   40,
-						TransmitDropped: 7,
-						TransmitPackets: 9,
+						ReceiveBytes:       101,
+						ReceiveBytesExact:  101,
+						ReceivePackets:     5,
+						TransmitBytes:      40,
+						TransmitBytesExact: 40,
+						TransmitDropped:    7,
+						TransmitPackets:    9,
 					},
 				},
+				Type:    DeviceTypeAccessPoint,
 				Uptime:  61 * time.Second,
 				Version: "1.0.0",
+				Uplink: &Uplink{
+					Type:       "wire",
+					Speed:      1000,
+					MaxSpeed:   1000,
+					FullDuplex: &fullDuplex,
+				},
+				IP:       net.IPv4(192, 168, 1, 2),
+				LastSeen: time.Unix(1451606461, 0),
+				State:    StateConnected,
 			},
 		},
 	}
@@ -302,3 +357,1810 @@ func TestDeviceUnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestClientRestartDeviceAndWait(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantMAC  = "de:ad:be:ef:de:ad"
+	)
+
+	var tests = []struct {
+		desc    string
+		states  []deviceStatus
+		timeout time.Duration
+		err     error
+	}{
+		{
+			desc: "disconnects then reconnects",
+			states: []deviceStatus{
+				{State: StateConnected, Uptime: 100},
+				{State: StateDisconnected},
+				{State: StateConnected, Uptime: 1},
+			},
+			timeout: time.Second,
+		},
+		{
+			desc: "reconnects before disconnect observed",
+			states: []deviceStatus{
+				{State: StateConnected, Uptime: 100},
+				{State: StateConnected, Uptime: 1},
+			},
+			timeout: time.Second,
+		},
+		{
+			desc: "timeout",
+			states: []deviceStatus{
+				{State: StateConnected, Uptime: 100},
+				{State: StateDisconnected},
+			},
+			timeout: 50 * time.Millisecond,
+			err:     errors.New("last observed state: disconnected"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var i int
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case fmt.Sprintf("/api/s/%s/cmd/devmgr", wantSite):
+					testHandler(t, http.MethodPost, r.URL.Path, &deviceCommand{
+						Command: "restart",
+						MAC:     wantMAC,
+					}, nil)(w, r)
+				case fmt.Sprintf("/api/s/%s/stat/device/%s", wantSite, wantMAC):
+					s := tt.states[len(tt.states)-1]
+					if i < len(tt.states) {
+						s = tt.states[i]
+					}
+					i++
+
+					v := struct {
+						Devices []deviceStatus `json:"data"`
+					}{
+						Devices: []deviceStatus{s},
+					}
+
+					testHandler(t, http.MethodGet, r.URL.Path, nil, v)(w, r)
+				default:
+					t.Fatalf("unexpected URL path: %v", r.URL.Path)
+				}
+			})
+			defer done()
+			c.pollInterval = 5 * time.Millisecond
+
+			err := c.RestartDeviceAndWait(context.Background(), wantSite, strings.ToUpper(wantMAC), tt.timeout)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeviceClockDrift(t *testing.T) {
+	now := time.Date(2016, time.January, 01, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		desc    string
+		d       *Device
+		drift   time.Duration
+		drifted bool
+	}{
+		{
+			desc: "no startup time",
+			d: &Device{
+				Uptime: time.Hour,
+			},
+		},
+		{
+			desc: "in sync",
+			d: &Device{
+				StartupTime: now.Add(-time.Hour),
+				Uptime:      time.Hour,
+			},
+		},
+		{
+			desc: "ahead within threshold",
+			d: &Device{
+				StartupTime: now.Add(-time.Hour + 30*time.Second),
+				Uptime:      time.Hour,
+			},
+			drift: 30 * time.Second,
+		},
+		{
+			desc: "ahead",
+			d: &Device{
+				StartupTime: now.Add(-time.Hour + 10*time.Minute),
+				Uptime:      time.Hour,
+			},
+			drift:   10 * time.Minute,
+			drifted: true,
+		},
+		{
+			desc: "behind",
+			d: &Device{
+				StartupTime: now.Add(-2 * time.Hour),
+				Uptime:      time.Hour,
+			},
+			drift:   -time.Hour,
+			drifted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			drift, drifted := tt.d.ClockDrift(now, time.Minute)
+
+			if want, got := tt.drift, drift; want != got {
+				t.Fatalf("unexpected drift:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+
+			if want, got := tt.drifted, drifted; want != got {
+				t.Fatalf("unexpected drifted value:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceRadioChannelWidthNumber(t *testing.T) {
+	d := new(Device)
+	if err := d.UnmarshalJSON([]byte(`{"inform_ip":"192.168.1.1","radio_table":[{"ht":40}]}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want, got := 40, d.Radios[0].ChannelWidth; want != got {
+		t.Fatalf("unexpected channel width:\n- want: %d\n-  got: %d", want, got)
+	}
+}
+
+func TestDeviceRadioChannels(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		b        string
+		channels []int
+		dfs      bool
+	}{
+		{
+			desc: "not reported",
+			b:    `{"inform_ip":"192.168.1.1","radio_table":[{"name":"wifi0"}]}`,
+		},
+		{
+			desc:     "none allowed",
+			b:        `{"inform_ip":"192.168.1.1","radio_table":[{"name":"wifi0","channels":[]}]}`,
+			channels: []int{},
+		},
+		{
+			desc:     "DFS",
+			b:        `{"inform_ip":"192.168.1.1","radio_table":[{"name":"wifi1","has_dfs":true,"channels":[36,"52",100]}]}`,
+			channels: []int{36, 52, 100},
+			dfs:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			r := d.Radios[0]
+			if want, got := tt.channels, r.AllowedChannels; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected allowed channels:\n- want: %#v\n-  got: %#v", want, got)
+			}
+			if want, got := tt.dfs, r.DFS; want != got {
+				t.Fatalf("unexpected DFS support:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceVAPs(t *testing.T) {
+	d := new(Device)
+	if err := d.UnmarshalJSON([]byte(`{"inform_ip":"192.168.1.1","vap_table":[
+		{"bssid":"de:ad:be:ef:00:01","essid":"home","num_sta":3,"radio":"ng"},
+		{"bssid":"de:ad:be:ef:00:02","essid":"home","radio":"na"}
+	]}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []*VAP{
+		{
+			BSSID:    net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01},
+			ESSID:    "home",
+			Radio:    radio24GHz,
+			Stations: 3,
+		},
+		{
+			BSSID: net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x02},
+			ESSID: "home",
+			Radio: radio5GHz,
+		},
+	}
+
+	if got := d.VAPs; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected VAPs:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestDeviceStatsExactBytes(t *testing.T) {
+	d := new(Device)
+	if err := d.UnmarshalJSON([]byte(`{"inform_ip":"192.168.1.1","stat":{"bytes":9007199254740993,"rx_bytes":1.5e3}}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want, got := uint64(9007199254740993), d.Stats.TotalBytesExact; want != got {
+		t.Fatalf("unexpected exact total bytes:\n- want: %d\n-  got: %d", want, got)
+	}
+	if want, got := uint64(1500), d.Stats.All.ReceiveBytesExact; want != got {
+		t.Fatalf("unexpected exact receive bytes:\n- want: %d\n-  got: %d", want, got)
+	}
+}
+
+func TestDeviceStatsWANs(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    string
+		wans []*WANStats
+	}{
+		{
+			desc: "access point",
+			b:    `{"inform_ip":"192.168.1.1","type":"uap"}`,
+		},
+		{
+			desc: "single WAN",
+			b:    `{"inform_ip":"192.168.1.1","type":"ugw","wan1":{"name":"eth0","up":true,"rx_bytes":100,"tx_bytes":50}}`,
+			wans: []*WANStats{{
+				Name:               "wan1",
+				Interface:          "eth0",
+				Up:                 true,
+				ReceiveBytes:       100,
+				ReceiveBytesExact:  100,
+				TransmitBytes:      50,
+				TransmitBytesExact: 50,
+			}},
+		},
+		{
+			desc: "dual WAN",
+			b:    `{"inform_ip":"192.168.1.1","type":"udm","wan1":{"name":"eth8","up":false},"wan2":{"name":"eth9","up":true,"rx_packets":2}}`,
+			wans: []*WANStats{
+				{
+					Name:      "wan1",
+					Interface: "eth8",
+				},
+				{
+					Name:           "wan2",
+					Interface:      "eth9",
+					Up:             true,
+					ReceivePackets: 2,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.wans, d.Stats.WANs; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected WANs:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceStatsUplinkLatency(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		b       string
+		latency time.Duration
+	}{
+		{
+			desc: "gateway not measured",
+			b:    `{"inform_ip":"192.168.1.1","type":"ugw"}`,
+		},
+		{
+			desc:    "gateway",
+			b:       `{"inform_ip":"192.168.1.1","type":"ugw","uplink":{"latency":12.5}}`,
+			latency: 12500 * time.Microsecond,
+		},
+		{
+			desc: "switch",
+			b:    `{"inform_ip":"192.168.1.1","type":"usw","uplink":{"latency":3}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.latency, d.Stats.UplinkLatency; want != got {
+				t.Fatalf("unexpected uplink latency:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceHWCaps(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		b        string
+		boardRev int
+		caps     HWCaps
+	}{
+		{
+			desc: "not reported",
+			b:    `{"inform_ip":"192.168.1.1"}`,
+		},
+		{
+			desc:     "OK",
+			b:        `{"inform_ip":"192.168.1.1","board_rev":21,"hw_caps":1026}`,
+			boardRev: 21,
+			caps:     1026,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.boardRev, d.BoardRev; want != got {
+				t.Fatalf("unexpected board revision:\n- want: %d\n-  got: %d", want, got)
+			}
+			if want, got := tt.caps, d.HWCaps; want != got {
+				t.Fatalf("unexpected hardware capabilities:\n- want: %d\n-  got: %d", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceCapabilities(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		b       string
+		poe     bool
+		fiveGHz bool
+	}{
+		{
+			desc: "none",
+			b:    `{"inform_ip":"192.168.1.1"}`,
+		},
+		{
+			desc: "switch without PoE",
+			b:    `{"inform_ip":"192.168.1.1","port_table":[{"port_idx":1,"port_poe":false}]}`,
+		},
+		{
+			desc: "PoE switch",
+			b:    `{"inform_ip":"192.168.1.1","port_table":[{"port_idx":1},{"port_idx":2,"port_poe":true}]}`,
+			poe:  true,
+		},
+		{
+			desc: "2.4GHz access point",
+			b:    `{"inform_ip":"192.168.1.1","radio_table":[{"name":"wifi0","radio":"ng"}]}`,
+		},
+		{
+			desc:    "dual-band access point",
+			b:       `{"inform_ip":"192.168.1.1","radio_table":[{"name":"wifi0","radio":"ng"},{"name":"wifi1","radio":"na"}]}`,
+			fiveGHz: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.poe, d.SupportsPoE(); want != got {
+				t.Fatalf("unexpected PoE support:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := tt.fiveGHz, d.Supports5GHz(); want != got {
+				t.Fatalf("unexpected 5GHz support:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceThermal(t *testing.T) {
+	d := new(Device)
+	if err := d.UnmarshalJSON([]byte(`{"inform_ip":"192.168.1.1","overheating":true,"fan_level":3}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !d.Overheating {
+		t.Fatal("expected Device to be overheating")
+	}
+	if want, got := 3, d.FanLevel; want != got {
+		t.Fatalf("unexpected fan level:\n- want: %d\n-  got: %d", want, got)
+	}
+}
+
+func TestDeviceScanning(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		b        string
+		scanning bool
+	}{
+		{
+			desc: "not reported",
+			b:    `{"inform_ip":"192.168.1.1"}`,
+		},
+		{
+			desc:     "scanning",
+			b:        `{"inform_ip":"192.168.1.1","scanning":true}`,
+			scanning: true,
+		},
+		{
+			desc:     "spectrum scanning",
+			b:        `{"inform_ip":"192.168.1.1","spectrum_scanning":true}`,
+			scanning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.scanning, d.Scanning; want != got {
+				t.Fatalf("unexpected scanning state:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceConnectRequestIP(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    string
+		ip   net.IP
+	}{
+		{
+			desc: "not reported",
+			b:    `{"inform_ip":"192.168.1.1"}`,
+		},
+		{
+			desc: "behind NAT",
+			b:    `{"inform_ip":"192.168.1.1","connect_request_ip":"203.0.113.10"}`,
+			ip:   net.IPv4(203, 0, 113, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.ip, d.ConnectRequestIP; !want.Equal(got) || (want == nil) != (got == nil) {
+				t.Fatalf("unexpected connect request IP:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceIdentity(t *testing.T) {
+	d := new(Device)
+	if err := d.UnmarshalJSON([]byte(`{"inform_ip":"192.168.1.1","ip":"","mac":""}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.IP != nil || d.MAC != nil {
+		t.Fatalf("unexpected IP or MAC for unprovisioned device: %v, %v", d.IP, d.MAC)
+	}
+	if !d.LastSeen.IsZero() {
+		t.Fatalf("unexpected last seen time for unseen device: %v", d.LastSeen)
+	}
+}
+
+func TestDevicePorts(t *testing.T) {
+	d := new(Device)
+	err := d.UnmarshalJSON([]byte(`{
+	"inform_ip": "192.168.1.1",
+	"port_table": [
+		{
+			"port_idx": 1,
+			"name": "Port 1",
+			"up": true,
+			"speed": 1000,
+			"full_duplex": true,
+			"poe_enable": true,
+			"poe_mode": "auto",
+			"poe_power": "2.81",
+			"rx_bytes": 9007199254740993,
+			"tx_bytes": 20
+		},
+		{
+			"port_idx": 2,
+			"name": "Port 2",
+			"poe_mode": "off"
+		}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []*Port{
+		{
+			PortIndex:     1,
+			Name:          "Port 1",
+			Up:            true,
+			Speed:         1000,
+			FullDuplex:    true,
+			PoEEnabled:    true,
+			PoEMode:       "auto",
+			PoEPower:      2.81,
+			ReceiveBytes:  9007199254740993,
+			TransmitBytes: 20,
+		},
+		{
+			PortIndex: 2,
+			Name:      "Port 2",
+			PoEMode:   "off",
+		},
+	}
+
+	if got := d.Ports; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Ports:\n- want: %+v\n-  got: %+v", want, got)
+	}
+}
+
+func TestDevicePowerUsage(t *testing.T) {
+	var tests = []struct {
+		desc  string
+		b     string
+		power float64
+	}{
+		{
+			desc: "not reported",
+			b:    `{"inform_ip":"192.168.1.1"}`,
+		},
+		{
+			desc:  "total used power",
+			b:     `{"inform_ip":"192.168.1.1","total_used_power":42.5,"power":"5"}`,
+			power: 42.5,
+		},
+		{
+			desc:  "power string",
+			b:     `{"inform_ip":"192.168.1.1","power":"12.25"}`,
+			power: 12.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.power, d.PowerUsage; want != got {
+				t.Fatalf("unexpected power usage:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceTotalPoEPower(t *testing.T) {
+	var tests = []struct {
+		desc  string
+		b     string
+		power float64
+	}{
+		{
+			desc: "no ports",
+			b:    `{"inform_ip":"192.168.1.1"}`,
+		},
+		{
+			desc: "switch",
+			b: `{
+	"inform_ip": "192.168.1.1",
+	"total_used_power": 7.5,
+	"port_table": [
+		{"port_idx": 1, "poe_power": "2.5"},
+		{"port_idx": 2, "poe_power": "5.0"},
+		{"port_idx": 3}
+	]
+}`,
+			power: 7.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.power, d.TotalPoEPower(); want != got {
+				t.Fatalf("unexpected total PoE power:\n- want: %v\n-  got: %v", want, got)
+			}
+
+			// A switch's total PoE power matches its reported power usage.
+			if want, got := d.PowerUsage, d.TotalPoEPower(); want != got {
+				t.Fatalf("total PoE power does not match power usage:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceType(t *testing.T) {
+	var tests = []struct {
+		s   string
+		t   DeviceType
+		str string
+	}{
+		{s: "uap", t: DeviceTypeAccessPoint, str: "access point"},
+		{s: "usw", t: DeviceTypeSwitch, str: "switch"},
+		{s: "ugw", t: DeviceTypeGateway, str: "gateway"},
+		{s: "udm", t: DeviceTypeGateway, str: "gateway"},
+		{s: "", t: DeviceTypeUnknown, str: "unknown"},
+		{s: "foo", t: DeviceTypeUnknown, str: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			typ := parseDeviceType(tt.s)
+			if want, got := tt.t, typ; want != got {
+				t.Fatalf("unexpected DeviceType:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+
+			if want, got := tt.str, typ.String(); want != got {
+				t.Fatalf("unexpected DeviceType string:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceState(t *testing.T) {
+	var tests = []struct {
+		b   string
+		s   DeviceState
+		str string
+	}{
+		{b: `{}`, s: StateDisconnected, str: "disconnected"},
+		{b: `{"state":1}`, s: StateConnected, str: "connected"},
+		{b: `{"state":6}`, s: StateHeartbeatMissed, str: "heartbeat missed"},
+		{b: `{"state":10}`, s: StateAdoptFailed, str: "adoption failed"},
+		{b: `{"state":99}`, s: DeviceState(99), str: "DeviceState(99)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.b, func(t *testing.T) {
+			// Inform IP is required to unmarshal a Device.
+			b := strings.Replace(tt.b, "{", `{"inform_ip":"192.168.1.1",`, 1)
+			b = strings.Replace(b, ",}", "}", 1)
+
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.s, d.State; want != got {
+				t.Fatalf("unexpected DeviceState:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+
+			if want, got := tt.str, d.State.String(); want != got {
+				t.Fatalf("unexpected DeviceState string:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceAdoptionState(t *testing.T) {
+	var tests = []struct {
+		b   string
+		s   AdoptionState
+		str string
+	}{
+		{b: `{"state":2}`, s: AdoptionPending, str: "pending"},
+		{b: `{"state":7}`, s: AdoptionAdopting, str: "adopting"},
+		{b: `{"state":0,"two_phase_adopt":true}`, s: AdoptionAdopting, str: "adopting"},
+		{b: `{"state":5,"adopted":true}`, s: AdoptionProvisioning, str: "provisioning"},
+		{b: `{"state":1,"adopted":true}`, s: AdoptionAdopted, str: "adopted"},
+		{b: `{"state":0,"adopted":true}`, s: AdoptionAdopted, str: "adopted"},
+		{b: `{"state":10}`, s: AdoptionFailed, str: "failed"},
+		{b: `{"state":1}`, s: AdoptionUnknown, str: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.b, func(t *testing.T) {
+			// Inform IP is required to unmarshal a Device.
+			b := strings.Replace(tt.b, "{", `{"inform_ip":"192.168.1.1",`, 1)
+
+			d := new(Device)
+			if err := d.UnmarshalJSON([]byte(b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			s := d.AdoptionState()
+			if want, got := tt.s, s; want != got {
+				t.Fatalf("unexpected AdoptionState:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+
+			if want, got := tt.str, s.String(); want != got {
+				t.Fatalf("unexpected AdoptionState string:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceStations(t *testing.T) {
+	var tests = []struct {
+		desc               string
+		d                  *Device
+		total, guest, user int
+	}{
+		{
+			desc: "no radios",
+			d:    &Device{},
+		},
+		{
+			desc: "radio without stats",
+			d: &Device{
+				Radios: []*Radio{{Name: "wifi0"}},
+			},
+		},
+		{
+			desc: "OK",
+			d: &Device{
+				Radios: []*Radio{
+					{
+						Stats: &RadioStationsStats{
+							NumberStations:      3,
+							NumberGuestStations: 1,
+							NumberUserStations:  2,
+						},
+					},
+					{
+						Stats: &RadioStationsStats{
+							NumberStations:      5,
+							NumberGuestStations: 0,
+							NumberUserStations:  5,
+						},
+					},
+				},
+			},
+			total: 8,
+			guest: 1,
+			user:  7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if want, got := tt.total, tt.d.TotalStations(); want != got {
+				t.Fatalf("unexpected total stations:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+			if want, got := tt.guest, tt.d.GuestStations(); want != got {
+				t.Fatalf("unexpected guest stations:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+			if want, got := tt.user, tt.d.UserStations(); want != got {
+				t.Fatalf("unexpected user stations:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientRestartDevice(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/devmgr", wantSite),
+		map[string]string{
+			"cmd": "restart",
+			"mac": "de:ad:be:ef:00:01",
+		},
+		nil,
+	))
+	defer done()
+
+	mac := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+	if err := c.RestartDevice(wantSite, mac); err != nil {
+		t.Fatalf("unexpected error from Client.RestartDevice: %v", err)
+	}
+
+	if err := c.RestartDevice(wantSite, nil); err == nil {
+		t.Fatal("expected an error for an empty MAC address")
+	}
+}
+
+func TestClientRestartDeviceNotFound(t *testing.T) {
+	const wantMsg = "api.err.UnknownDevice"
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"` + wantMsg + `"},"data":[]}`))
+	})
+	defer done()
+
+	err := c.RestartDevice("default", net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != wantMsg {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", wantMsg, err)
+	}
+}
+
+func TestClientAdoptDevice(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/devmgr", wantSite),
+		map[string]string{
+			"cmd": "adopt",
+			"mac": "de:ad:be:ef:00:01",
+		},
+		nil,
+	))
+	defer done()
+
+	mac := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+	if err := c.AdoptDevice(wantSite, mac); err != nil {
+		t.Fatalf("unexpected error from Client.AdoptDevice: %v", err)
+	}
+
+	if err := c.AdoptDevice(wantSite, mac[:4]); err == nil {
+		t.Fatal("expected an error for a short MAC address")
+	}
+}
+
+func TestClientForgetDevice(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/sitemgr", wantSite),
+		map[string]string{
+			"cmd": "delete-device",
+			"mac": "de:ad:be:ef:00:01",
+		},
+		nil,
+	))
+	defer done()
+
+	mac := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+	if err := c.ForgetDevice(wantSite, mac); err != nil {
+		t.Fatalf("unexpected error from Client.ForgetDevice: %v", err)
+	}
+
+	if err := c.ForgetDevice(wantSite, nil); err == nil {
+		t.Fatal("expected an error for an empty MAC address")
+	}
+}
+
+func TestClientResetDeviceStats(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/devmgr", wantSite),
+		map[string]string{
+			"cmd": "reset-stats",
+			"mac": "de:ad:be:ef:00:01",
+		},
+		nil,
+	))
+	defer done()
+
+	if err := c.ResetDeviceStats(wantSite, "DE-AD-BE-EF-00-01"); err != nil {
+		t.Fatalf("unexpected error from Client.ResetDeviceStats: %v", err)
+	}
+
+	if err := c.ResetDeviceStats(wantSite, "foo"); err == nil {
+		t.Fatal("expected an error for an invalid MAC address")
+	}
+}
+
+func TestClientResetDeviceStatsUnsupported(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"api.err.UnknownCommand"},"data":[]}`))
+	})
+	defer done()
+
+	err := c.ResetDeviceStats("default", "de:ad:be:ef:00:01")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrUnsupported, err)
+	}
+}
+
+func TestClientSetDeviceConfigNetwork(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	var tests = []struct {
+		desc                 string
+		dhcp                 bool
+		ip, netmask, gateway string
+		put                  map[string]interface{}
+		err                  error
+	}{
+		{
+			desc: "DHCP with static IP",
+			dhcp: true,
+			ip:   "192.168.1.10",
+			err:  errors.New("must not be specified"),
+		},
+		{
+			desc:    "static invalid IP",
+			ip:      "foo",
+			netmask: "255.255.255.0",
+			gateway: "192.168.1.1",
+			err:     errors.New("invalid static config network IP"),
+		},
+		{
+			desc:    "static missing gateway",
+			ip:      "192.168.1.10",
+			netmask: "255.255.255.0",
+			err:     errors.New("invalid static config network gateway"),
+		},
+		{
+			desc:    "OK static",
+			ip:      "192.168.1.10",
+			netmask: "255.255.255.0",
+			gateway: "192.168.1.1",
+			put: map[string]interface{}{
+				"config_network": map[string]interface{}{
+					"dns1":    "192.168.1.1",
+					"gateway": "192.168.1.1",
+					"ip":      "192.168.1.10",
+					"netmask": "255.255.255.0",
+					"type":    "static",
+				},
+			},
+		},
+		{
+			desc: "OK DHCP unchanged",
+			dhcp: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var updated bool
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID), nil,
+						map[string]interface{}{
+							"data": []map[string]interface{}{{
+								"_id":  wantID,
+								"name": "AP",
+								"config_network": map[string]interface{}{
+									"type": "dhcp",
+									"dns1": "192.168.1.1",
+								},
+							}},
+						},
+					)(w, r)
+				case http.MethodPut:
+					// Only changed fields may be written.
+					updated = true
+					testHandler(t, http.MethodPut, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID),
+						tt.put,
+						nil,
+					)(w, r)
+				}
+			})
+			defer done()
+
+			err := c.SetDeviceConfigNetwork(wantSite, wantID, tt.dhcp, tt.ip, tt.netmask, tt.gateway)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if tt.err != nil {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error from Client.SetDeviceConfigNetwork: %v", err)
+			}
+
+			if want, got := tt.put != nil, updated; want != got {
+				t.Fatalf("unexpected device update:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientPendingDevices(t *testing.T) {
+	const wantSite = "default"
+
+	states := map[string]DeviceState{
+		"connected":    StateConnected,
+		"pending":      StatePending,
+		"upgrading":    StateUpgrading,
+		"provisioning": StateProvisioning,
+		"adopting":     StateAdopting,
+		"failed":       StateAdoptFailed,
+		"disconnected": StateDisconnected,
+	}
+
+	var devices []device
+	for id, state := range states {
+		devices = append(devices, device{
+			ID:       id,
+			InformIP: "192.168.1.1",
+			State:    state,
+		})
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/stat/device", wantSite),
+		nil,
+		struct {
+			Devices []device `json:"data"`
+		}{Devices: devices},
+	))
+	defer done()
+
+	pending, err := c.PendingDevices(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.PendingDevices: %v", err)
+	}
+
+	ids := make([]string, 0, len(pending))
+	for _, d := range pending {
+		ids = append(ids, d.ID)
+	}
+	sort.Strings(ids)
+
+	if want, got := []string{"adopting", "pending", "provisioning", "upgrading"}, ids; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected pending Devices:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientDevicesPage(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/stat/device", wantSite),
+		&pageQuery{Start: 10, Limit: 2},
+		struct {
+			Devices []device `json:"data"`
+		}{Devices: []device{
+			{ID: "10", InformIP: "192.168.1.1"},
+			{ID: "11", InformIP: "192.168.1.1"},
+		}},
+	))
+	defer done()
+
+	devices, err := c.DevicesPage(wantSite, 10, 2)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.DevicesPage: %v", err)
+	}
+
+	if want, got := 2, len(devices); want != got {
+		t.Fatalf("unexpected number of Devices:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+}
+
+func TestClientDevicesEach(t *testing.T) {
+	const (
+		wantSite = "default"
+		total    = devicesPageSize + devicesPageSize/2
+	)
+
+	all := make([]device, 0, total)
+	for i := 0; i < total; i++ {
+		all = append(all, device{
+			ID:       fmt.Sprint(i),
+			InformIP: "192.168.1.1",
+		})
+	}
+
+	var pages int
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pages++
+
+		var q pageQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			t.Fatalf("failed to decode page query: %v", err)
+		}
+
+		end := q.Start + q.Limit
+		if end > len(all) {
+			end = len(all)
+		}
+
+		w.Header().Set("Content-Type", jsonContentType)
+		_ = json.NewEncoder(w).Encode(struct {
+			Devices []device `json:"data"`
+		}{Devices: all[q.Start:end]})
+	})
+	defer done()
+
+	var ids []string
+	err := c.DevicesEach(wantSite, func(d *Device) error {
+		ids = append(ids, d.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error from Client.DevicesEach: %v", err)
+	}
+
+	if want, got := total, len(ids); want != got {
+		t.Fatalf("unexpected number of Devices:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+	if want, got := fmt.Sprint(total-1), ids[len(ids)-1]; want != got {
+		t.Fatalf("unexpected last Device:\n- want: %v\n-  got: %v", want, got)
+	}
+	if want, got := 2, pages; want != got {
+		t.Fatalf("unexpected number of pages:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+
+	// An error from fn stops iteration.
+	errStop := errors.New("stop")
+	pages = 0
+	err = c.DevicesEach(wantSite, func(d *Device) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", errStop, err)
+	}
+	if want, got := 1, pages; want != got {
+		t.Fatalf("unexpected number of pages:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+}
+
+func TestClientDevicesEachIgnoresPaging(t *testing.T) {
+	const wantSite = "default"
+
+	for _, total := range []int{devicesPageSize, devicesPageSize * 2} {
+		t.Run(fmt.Sprintf("%d devices", total), func(t *testing.T) {
+			all := make([]device, 0, total)
+			for i := 0; i < total; i++ {
+				all = append(all, device{
+					ID:       fmt.Sprint(i),
+					InformIP: "192.168.1.1",
+				})
+			}
+
+			// The server ignores the paging body and always returns every
+			// Device.
+			var pages int
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				pages++
+				if pages > 3 {
+					t.Fatal("too many pages requested")
+				}
+
+				w.Header().Set("Content-Type", jsonContentType)
+				_ = json.NewEncoder(w).Encode(struct {
+					Devices []device `json:"data"`
+				}{Devices: all})
+			})
+			defer done()
+
+			seen := make(map[string]int)
+			err := c.DevicesEach(wantSite, func(d *Device) error {
+				seen[d.ID]++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error from Client.DevicesEach: %v", err)
+			}
+
+			if want, got := total, len(seen); want != got {
+				t.Fatalf("unexpected number of Devices:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+			for id, n := range seen {
+				if n != 1 {
+					t.Fatalf("Device %s visited %d times", id, n)
+				}
+			}
+		})
+	}
+}
+
+func TestClientWANLatency(t *testing.T) {
+	const wantSite = "default"
+
+	var tests = []struct {
+		desc    string
+		devices []map[string]interface{}
+		latency time.Duration
+		err     error
+	}{
+		{
+			desc: "no gateway",
+			devices: []map[string]interface{}{
+				{"inform_ip": "192.168.1.2", "type": "uap"},
+			},
+			err: ErrNotFound,
+		},
+		{
+			desc: "OK",
+			devices: []map[string]interface{}{
+				{"inform_ip": "192.168.1.2", "type": "uap"},
+				{"inform_ip": "192.168.1.1", "type": "ugw", "uplink": map[string]interface{}{"latency": 8}},
+			},
+			latency: 8 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/stat/device", wantSite),
+				nil,
+				map[string]interface{}{"data": tt.devices},
+			))
+			defer done()
+
+			latency, err := c.WANLatency(wantSite)
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+
+			if want, got := tt.latency, latency; want != got {
+				t.Fatalf("unexpected WAN latency:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientSetRadioName(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	var tests = []struct {
+		desc    string
+		current string
+		put     map[string]interface{}
+		err     error
+	}{
+		{
+			desc:    "no such radio",
+			current: "wifi2",
+			err:     errors.New(`has no radio named "wifi2"`),
+		},
+		{
+			desc:    "OK",
+			current: "wifi1",
+			put: map[string]interface{}{
+				"radio_table": []map[string]interface{}{
+					{"name": "wifi0", "radio": "ng", "tx_power_mode": "auto"},
+					{"name": "lobby-5g", "radio": "na", "tx_power_mode": "high"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID), nil,
+						map[string]interface{}{
+							"data": []map[string]interface{}{{
+								"_id": wantID,
+								"radio_table": []map[string]interface{}{
+									{"name": "wifi0", "radio": "ng", "tx_power_mode": "auto"},
+									{"name": "wifi1", "radio": "na", "tx_power_mode": "high"},
+								},
+							}},
+						},
+					)(w, r)
+				case http.MethodPut:
+					testHandler(t, http.MethodPut, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID),
+						tt.put,
+						nil,
+					)(w, r)
+				}
+			})
+			defer done()
+
+			err := c.SetRadioName(wantSite, wantID, tt.current, "lobby-5g")
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error from Client.SetRadioName: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientRenameDevice(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPut,
+		fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID),
+		map[string]string{"name": "lobby-ap"},
+		nil,
+	))
+	defer done()
+
+	if err := c.RenameDevice(wantSite, wantID, "lobby-ap"); err != nil {
+		t.Fatalf("unexpected error from Client.RenameDevice: %v", err)
+	}
+
+	if err := c.RenameDevice(wantSite, wantID, ""); err == nil {
+		t.Fatal("expected an error for an empty name")
+	}
+}
+
+func TestClientRenameDeviceByMAC(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	wantMAC := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+
+	var tests = []struct {
+		desc    string
+		devices []map[string]interface{}
+		err     error
+	}{
+		{
+			desc:    "not found",
+			devices: []map[string]interface{}{},
+			err:     ErrNotFound,
+		},
+		{
+			desc: "OK",
+			devices: []map[string]interface{}{{
+				"_id":       wantID,
+				"inform_ip": "192.168.1.1",
+				"mac":       wantMAC.String(),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/stat/device/%s", wantSite, wantMAC), nil,
+						map[string]interface{}{"data": tt.devices},
+					)(w, r)
+				case http.MethodPut:
+					testHandler(t, http.MethodPut, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID),
+						map[string]string{"name": "lobby-ap"},
+						nil,
+					)(w, r)
+				}
+			})
+			defer done()
+
+			err := c.RenameDeviceByMAC(wantSite, wantMAC, "lobby-ap")
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientSetPortPoE(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	wantMAC := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+
+	var tests = []struct {
+		desc string
+		port int
+		mode string
+		put  map[string]interface{}
+		err  error
+	}{
+		{
+			desc: "bad mode",
+			port: 1,
+			mode: "on",
+			err:  errors.New(`invalid PoE mode: "on"`),
+		},
+		{
+			desc: "bad port",
+			mode: "off",
+			err:  errors.New("invalid port index: 0"),
+		},
+		{
+			desc: "existing override",
+			port: 2,
+			mode: "off",
+			put: map[string]interface{}{
+				"port_overrides": []map[string]interface{}{
+					{"port_idx": 1, "name": "uplink"},
+					{"port_idx": 2, "poe_mode": "off", "portconf_id": "abc"},
+				},
+			},
+		},
+		{
+			desc: "new override",
+			port: 3,
+			mode: "pasv24",
+			put: map[string]interface{}{
+				"port_overrides": []map[string]interface{}{
+					{"port_idx": 1, "name": "uplink"},
+					{"port_idx": 2, "poe_mode": "auto", "portconf_id": "abc"},
+					{"port_idx": 3, "poe_mode": "pasv24"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/stat/device/"):
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/stat/device/%s", wantSite, wantMAC), nil,
+						map[string]interface{}{
+							"data": []map[string]interface{}{{
+								"_id":       wantID,
+								"inform_ip": "192.168.1.1",
+								"mac":       wantMAC.String(),
+							}},
+						},
+					)(w, r)
+				case r.Method == http.MethodGet:
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID), nil,
+						map[string]interface{}{
+							"data": []map[string]interface{}{{
+								"_id": wantID,
+								"port_overrides": []map[string]interface{}{
+									{"port_idx": 1, "name": "uplink"},
+									{"port_idx": 2, "poe_mode": "auto", "portconf_id": "abc"},
+								},
+							}},
+						},
+					)(w, r)
+				case r.Method == http.MethodPut:
+					testHandler(t, http.MethodPut, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID),
+						tt.put,
+						nil,
+					)(w, r)
+				}
+			})
+			defer done()
+
+			err := c.SetPortPoE(wantSite, wantMAC, tt.port, tt.mode)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error from Client.SetPortPoE: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientSetDeviceSSH(t *testing.T) {
+	const (
+		wantSite     = "default"
+		wantID       = "abcdef1234567890"
+		wantPassword = "hunter2"
+	)
+
+	var tests = []struct {
+		desc     string
+		username string
+		status   int
+		put      map[string]interface{}
+		err      error
+	}{
+		{
+			desc: "no username",
+			err:  errors.New("SSH username must not be empty"),
+		},
+		{
+			desc:     "rejected",
+			username: "admin",
+			status:   http.StatusBadRequest,
+			err:      errors.New("api.err.Invalid"),
+		},
+		{
+			desc:     "OK",
+			username: "admin",
+			put: map[string]interface{}{
+				"x_ssh_username": "admin",
+				"x_ssh_password": wantPassword,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID), nil,
+						map[string]interface{}{
+							"data": []map[string]interface{}{{
+								"_id":            wantID,
+								"x_ssh_username": "ubnt",
+								"x_ssh_password": "ubnt",
+							}},
+						},
+					)(w, r)
+				case http.MethodPut:
+					if tt.status != 0 {
+						w.Header().Set("Content-Type", jsonContentType)
+						w.WriteHeader(tt.status)
+						_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"api.err.Invalid"},"data":[]}`))
+						return
+					}
+
+					testHandler(t, http.MethodPut, fmt.Sprintf("/api/s/%s/rest/device/%s", wantSite, wantID),
+						tt.put,
+						nil,
+					)(w, r)
+				}
+			})
+			defer done()
+
+			err := c.SetDeviceSSH(wantSite, wantID, tt.username, wantPassword)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error from Client.SetDeviceSSH: %v", err)
+			}
+			if strings.Contains(errStr(err), wantPassword) {
+				t.Fatalf("error contains password: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientDeviceByMAC(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	mac := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01}
+
+	var tests = []struct {
+		desc    string
+		devices []map[string]interface{}
+		err     error
+	}{
+		{
+			desc:    "not found",
+			devices: []map[string]interface{}{},
+			err:     ErrNotFound,
+		},
+		{
+			desc: "OK",
+			devices: []map[string]interface{}{{
+				"_id":       wantID,
+				"mac":       "de:ad:be:ef:00:01",
+				"inform_ip": "192.168.1.1",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/stat/device/de:ad:be:ef:00:01", wantSite),
+				nil,
+				map[string]interface{}{"data": tt.devices},
+			))
+			defer done()
+
+			d, err := c.DeviceByMAC(wantSite, mac)
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if err != nil {
+				return
+			}
+
+			if want, got := wantID, d.ID; want != got {
+				t.Fatalf("unexpected Device ID:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := mac, d.MAC; !bytes.Equal(want, got) {
+				t.Fatalf("unexpected Device MAC:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientSetAllDeviceLEDs(t *testing.T) {
+	const wantSite = "default"
+
+	devices := []device{
+		{ID: "a", Name: "ap-a", InformIP: "192.168.1.1", State: StateConnected},
+		{ID: "b", Name: "ap-b", InformIP: "192.168.1.1", State: StateConnected},
+		{ID: "c", Name: "ap-c", InformIP: "192.168.1.1", State: StateDisconnected},
+		{ID: "d", Name: "ap-d", InformIP: "192.168.1.1", State: StateConnected},
+	}
+
+	var (
+		mu      sync.Mutex
+		updated []string
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/s/%s/stat/device", wantSite) {
+			testHandler(t, http.MethodGet, r.URL.Path, nil, struct {
+				Devices []device `json:"data"`
+			}{Devices: devices})(w, r)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/s/%s/rest/device/", wantSite))
+		switch r.Method {
+		case http.MethodGet:
+			testHandler(t, http.MethodGet, r.URL.Path, nil, map[string]interface{}{
+				"data": []map[string]interface{}{{
+					"_id":          id,
+					"led_override": "default",
+				}},
+			})(w, r)
+		case http.MethodPut:
+			if id == "b" {
+				w.Header().Set("Content-Type", jsonContentType)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			mu.Lock()
+			updated = append(updated, id)
+			mu.Unlock()
+
+			testHandler(t, http.MethodPut, r.URL.Path, map[string]string{"led_override": "off"}, nil)(w, r)
+		}
+	})
+	defer done()
+
+	err := c.SetAllDeviceLEDs(wantSite, LEDModeOff)
+	if want, got := `failed to set LED mode for device "ap-b"`, errStr(err); !strings.Contains(got, want) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	sort.Strings(updated)
+	if want, got := []string{"a", "d"}, updated; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected updated Devices:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestNICRandomizedMAC(t *testing.T) {
+	for _, tt := range []struct {
+		mac net.HardwareAddr
+		ok  bool
+	}{
+		{mac: net.HardwareAddr{0x04, 0x18, 0xd6, 0xa0, 0x00, 0x01}},
+		{mac: net.HardwareAddr{0x06, 0x18, 0xd6, 0xa0, 0x00, 0x01}, ok: true},
+	} {
+		n := &NIC{MAC: tt.mac}
+		if want, got := tt.ok, n.RandomizedMAC(); want != got {
+			t.Fatalf("unexpected randomized MAC for %q:\n- want: %v\n-  got: %v",
+				tt.mac, want, got)
+		}
+	}
+}
+
+func TestUplinkDegraded(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		b        []byte
+		degraded bool
+	}{
+		{
+			desc: "no uplink",
+			b:    []byte(`{"inform_ip":"192.168.1.1"}`),
+		},
+		{
+			desc: "wired OK",
+			b:    []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wire","speed":1000,"max_speed":1000,"full_duplex":true}}`),
+		},
+		{
+			desc:     "wired slow",
+			b:        []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wire","speed":100,"max_speed":1000,"full_duplex":true}}`),
+			degraded: true,
+		},
+		{
+			desc:     "wired half duplex",
+			b:        []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wire","speed":100,"max_speed":1000,"full_duplex":false}}`),
+			degraded: true,
+		},
+		{
+			desc: "wired duplex not reported",
+			b:    []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wire","speed":1000,"max_speed":1000}}`),
+		},
+		{
+			desc: "wired max speed not reported",
+			b:    []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wire","speed":100,"full_duplex":true}}`),
+		},
+		{
+			desc:     "wired half duplex max speed not reported",
+			b:        []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wire","speed":100,"full_duplex":false}}`),
+			degraded: true,
+		},
+		{
+			desc: "wired no speed",
+			b:    []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wire"}}`),
+		},
+		{
+			desc: "wireless",
+			b:    []byte(`{"inform_ip":"192.168.1.1","uplink":{"type":"wireless","speed":0,"max_speed":0}}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var d Device
+			if err := d.UnmarshalJSON(tt.b); err != nil {
+				t.Fatalf("failed to unmarshal Device: %v", err)
+			}
+
+			var degraded bool
+			if d.Uplink != nil {
+				degraded = d.Uplink.Degraded()
+			}
+
+			if want, got := tt.degraded, degraded; want != got {
+				t.Fatalf("unexpected degraded uplink:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
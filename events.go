@@ -0,0 +1,416 @@
+package unifi
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Events returns all of the Events for a specified site name.
+func (c *Client) Events(siteName string) ([]*Event, error) {
+	events, _, err := c.events(context.Background(), siteName, nil)
+	return events, err
+}
+
+// EventsPage returns a page of at most limit Events for a specified site name,
+// beginning at offset start, along with the total number of Events available
+// across all pages.  The total is 0 if the UniFi Controller did not report
+// one.
+func (c *Client) EventsPage(siteName string, start int, limit int) ([]*Event, int, error) {
+	return c.events(context.Background(), siteName, &eventQuery{
+		Start: start,
+		Limit: limit,
+	})
+}
+
+// EventsSince returns at most limit of the Events for a specified site name
+// which occurred at or after since, newest first.  If limit is zero, all of
+// those Events are returned.
+func (c *Client) EventsSince(siteName string, since time.Time, limit int) ([]*Event, error) {
+	q := &eventQuery{
+		Limit: limit,
+		Sort:  "-time",
+	}
+	if d := time.Since(since); d > 0 {
+		q.Within = int(math.Ceil(d.Hours()))
+	}
+
+	events, _, err := c.events(context.Background(), siteName, q)
+	if err != nil {
+		return nil, err
+	}
+
+	// The UniFi Controller only accepts a window in hours, so trim any
+	// Events which are older than requested.  Events are sorted newest
+	// first, so older Events never displace newer ones within the limit.
+	recent := make([]*Event, 0, len(events))
+	for _, e := range events {
+		if !e.DateTime.Before(since) {
+			recent = append(recent, e)
+		}
+	}
+
+	return recent, nil
+}
+
+// events retrieves Events for a specified site name, using an optional query
+// to filter the results.  The total number of matching Events reported by the
+// UniFi Controller is also returned.
+func (c *Client) events(ctx context.Context, siteName string, q *eventQuery) ([]*Event, int, error) {
+	var v struct {
+		Meta   pageMeta `json:"meta"`
+		Events []*Event `json:"data"`
+	}
+
+	method := http.MethodGet
+	var body interface{}
+	if q != nil {
+		method = http.MethodPost
+		body = q
+	}
+
+	req, err := c.newRequest(
+		method,
+		fmt.Sprintf("/api/s/%s/stat/event", siteName),
+		body,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := c.do(req.WithContext(ctx), &v); err != nil {
+		return nil, 0, err
+	}
+
+	return v.Events, v.Meta.total(), nil
+}
+
+// WriteEventsCSV writes the Events which occurred on a specified site name
+// within the specified duration to w in CSV format, oldest first.  The CSV
+// begins with a header row, and contains the columns time, subsystem, key,
+// ap, client, and message.  Times are formatted using RFC 3339.
+func (c *Client) WriteEventsCSV(siteName string, w io.Writer, within time.Duration) error {
+	q := &eventQuery{}
+	if within > 0 {
+		q.Within = int(math.Ceil(within.Hours()))
+	}
+
+	events, _, err := c.events(context.Background(), siteName, q)
+	if err != nil {
+		return err
+	}
+
+	// The UniFi Controller only accepts a window in hours, so trim any
+	// Events which are older than requested.
+	var since time.Time
+	if within > 0 {
+		since = time.Now().Add(-within)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].DateTime.Before(events[j].DateTime)
+	})
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "subsystem", "key", "ap", "client", "message"}); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		if e.DateTime.Before(since) {
+			continue
+		}
+
+		var ap, client string
+		if e.APMAC != nil {
+			ap = e.APMAC.String()
+		}
+		if e.User != nil {
+			client = e.User.String()
+		}
+
+		if err := cw.Write([]string{
+			e.DateTime.Format(time.RFC3339),
+			e.Subsystem,
+			e.Key,
+			ap,
+			client,
+			e.Message,
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// An eventQuery is the raw structure of a query used to filter Events.
+type eventQuery struct {
+	Within int    `json:"within,omitempty"`
+	Start  int    `json:"_start,omitempty"`
+	Limit  int    `json:"_limit,omitempty"`
+	Sort   string `json:"_sort,omitempty"`
+}
+
+// An Event is a record of an occurrence on a site, such as a Station
+// connecting to or disconnecting from the network.
+type Event struct {
+	ID        string
+	APMAC     net.HardwareAddr
+	DateTime  time.Time
+	Hostname  string
+	Key       string
+	Message   string
+	Severity  Severity
+	SiteID    string
+	SSID      string
+	Subsystem string
+	User      net.HardwareAddr
+}
+
+// A Severity indicates how serious an Event is, as color-coded by the UniFi
+// Controller's web interface.
+type Severity int
+
+// List of possible Severity values.
+const (
+	SeverityUnknown Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityCritical
+)
+
+// String returns the string representation of a Severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// severityKeys maps Event keys to a Severity other than SeverityInfo.  Event
+// keys not present in this map are informational.
+var severityKeys = map[string]Severity{
+	// Devices which have stopped communicating with the controller.
+	"EVT_AP_Lost_Contact": SeverityCritical,
+	"EVT_SW_Lost_Contact": SeverityCritical,
+	"EVT_GW_Lost_Contact": SeverityCritical,
+
+	// Failed administrator logins.
+	"EVT_AD_LoginFailed": SeverityWarning,
+
+	// Intrusion prevention and rogue devices.
+	"EVT_IPS_IpsAlert":     SeverityCritical,
+	"EVT_AP_DetectRogueAP": SeverityWarning,
+
+	// Degraded network conditions.
+	"EVT_AP_RadarDetected":        SeverityWarning,
+	"EVT_AP_Isolated":             SeverityWarning,
+	"EVT_GW_WANTransition":        SeverityWarning,
+	"EVT_SW_StpPortBlocking":      SeverityWarning,
+	"EVT_SW_PoeOverload":          SeverityWarning,
+	"EVT_SW_PoeDisconnect":        SeverityWarning,
+	"EVT_AP_PossibleInterference": SeverityWarning,
+}
+
+// eventSeverity returns the Severity of an Event with the specified key.
+func eventSeverity(key string) Severity {
+	if s, ok := severityKeys[key]; ok {
+		return s
+	}
+
+	return SeverityInfo
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// an Event is unmarshaled.
+func (*Event) raw() interface{} { return new(event) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of an Event.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var ev event
+	if err := json.Unmarshal(b, &ev); err != nil {
+		return err
+	}
+
+	t, err := time.Parse(time.RFC3339, ev.DateTime)
+	if err != nil {
+		return err
+	}
+
+	// Not all Events are associated with a Station or access point.
+	var user net.HardwareAddr
+	if ev.User != "" {
+		user, err = net.ParseMAC(ev.User)
+		if err != nil {
+			return err
+		}
+	}
+
+	var ap net.HardwareAddr
+	if ev.AP != "" {
+		ap, err = net.ParseMAC(ev.AP)
+		if err != nil {
+			return err
+		}
+	}
+
+	*e = Event{
+		ID:        ev.ID,
+		APMAC:     ap,
+		DateTime:  t,
+		Hostname:  ev.Hostname,
+		Key:       ev.Key,
+		Message:   ev.Msg,
+		Severity:  eventSeverity(ev.Key),
+		SiteID:    ev.SiteID,
+		SSID:      ev.SSID,
+		Subsystem: ev.Subsystem,
+		User:      user,
+	}
+
+	return nil
+}
+
+// An event is the raw structure of an Event returned from the UniFi Controller
+// API.
+type event struct {
+	ID        string `json:"_id"`
+	AP        string `json:"ap"`
+	DateTime  string `json:"datetime"`
+	Hostname  string `json:"hostname"`
+	Key       string `json:"key"`
+	Msg       string `json:"msg"`
+	SiteID    string `json:"site_id"`
+	SSID      string `json:"ssid"`
+	Subsystem string `json:"subsystem"`
+	User      string `json:"user"`
+}
+
+// A PresenceEvent indicates that a Station connected to or disconnected from
+// a site.
+type PresenceEvent struct {
+	MAC       net.HardwareAddr
+	Hostname  string
+	Connected bool
+	At        time.Time
+}
+
+// presenceKeys maps Event keys which indicate Station presence changes to
+// whether or not the Station connected.
+var presenceKeys = map[string]bool{
+	// Wireless users and guests.
+	"EVT_WU_Connected":    true,
+	"EVT_WU_Disconnected": false,
+	"EVT_WG_Connected":    true,
+	"EVT_WG_Disconnected": false,
+
+	// Wired users and guests.
+	"EVT_LU_Connected":    true,
+	"EVT_LU_Disconnected": false,
+	"EVT_LG_Connected":    true,
+	"EVT_LG_Disconnected": false,
+}
+
+// PresenceStream polls the Events for a specified site name, and emits a
+// PresenceEvent on the returned channel whenever a Station connects or
+// disconnects.  Only Events which occur after PresenceStream is called are
+// emitted.
+//
+// If Events cannot be retrieved while polling, that poll is skipped and the
+// error is reported to the Client's PollError function, if set.  The
+// channel is closed when ctx is canceled or the Client is closed.
+func (c *Client) PresenceStream(ctx context.Context, siteName string) (<-chan PresenceEvent, error) {
+	q := &eventQuery{
+		Within: 1,
+		Sort:   "-time",
+	}
+
+	// Note the Events which have already occurred so they are not emitted.
+	events, _, err := c.events(ctx, siteName, q)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := eventIDs(events)
+
+	ch := make(chan PresenceEvent)
+	go func() {
+		defer close(ch)
+
+		t := time.NewTicker(c.pollInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.closed:
+				return
+			case <-t.C:
+			}
+
+			events, _, err := c.events(ctx, siteName, q)
+			if err != nil {
+				if ctx.Err() == nil && err != ErrClosed && c.PollError != nil {
+					c.PollError(err)
+				}
+				continue
+			}
+
+			// Events are sorted newest first, so emit them in reverse.
+			for i := len(events) - 1; i >= 0; i-- {
+				e := events[i]
+				if _, ok := seen[e.ID]; ok {
+					continue
+				}
+
+				connected, ok := presenceKeys[e.Key]
+				if !ok || e.User == nil {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-c.closed:
+					return
+				case ch <- PresenceEvent{
+					MAC:       e.User,
+					Hostname:  e.Hostname,
+					Connected: connected,
+					At:        e.DateTime,
+				}:
+				}
+			}
+
+			seen = eventIDs(events)
+		}
+	}()
+
+	return ch, nil
+}
+
+// eventIDs returns the set of IDs for the input Events.
+func eventIDs(events []*Event) map[string]struct{} {
+	ids := make(map[string]struct{}, len(events))
+	for _, e := range events {
+		ids[e.ID] = struct{}{}
+	}
+
+	return ids
+}
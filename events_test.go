@@ -0,0 +1,487 @@
+package unifi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientEvents(t *testing.T) {
+	const (
+		wantSite    = "default"
+		wantID      = "abcdef123457890"
+		wantKey     = "EVT_WU_Connected"
+		wantMessage = "User has connected"
+	)
+	var (
+		wantUser     = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad}
+		wantDateTime = time.Date(2016, time.January, 01, 0, 0, 0, 0, time.UTC)
+	)
+
+	wantEvent := &Event{
+		ID:       wantID,
+		DateTime: wantDateTime,
+		Key:      wantKey,
+		Message:  wantMessage,
+		Severity: SeverityInfo,
+		SSID:     "guest",
+		User:     wantUser,
+	}
+
+	v := struct {
+		Events []event `json:"data"`
+	}{
+		Events: []event{{
+			ID:       wantID,
+			DateTime: wantDateTime.Format(time.RFC3339),
+			Key:      wantKey,
+			Msg:      wantMessage,
+			SSID:     "guest",
+			User:     wantUser.String(),
+		}},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/stat/event", wantSite),
+		nil,
+		v,
+	))
+	defer done()
+
+	events, err := c.Events(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.Events: %v", err)
+	}
+
+	if want, got := 1, len(events); want != got {
+		t.Fatalf("unexpected number of Events:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+
+	if want, got := wantEvent, events[0]; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Event:\n- want: %#v\n-  got: %#v",
+			want, got)
+	}
+}
+
+func TestClientEventsPage(t *testing.T) {
+	const wantSite = "default"
+
+	var tests = []struct {
+		desc  string
+		meta  pageMeta
+		total int
+	}{
+		{
+			desc: "no total",
+			meta: pageMeta{},
+		},
+		{
+			desc:  "count",
+			meta:  pageMeta{Count: 3000},
+			total: 3000,
+		},
+		{
+			desc:  "total",
+			meta:  pageMeta{Count: 2, Total: 3000},
+			total: 3000,
+		},
+		{
+			desc:  "total count",
+			meta:  pageMeta{Count: 2, Total: 2, TotalCount: 3000},
+			total: 3000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := struct {
+				Meta   pageMeta `json:"meta"`
+				Events []event  `json:"data"`
+			}{
+				Meta: tt.meta,
+				Events: []event{
+					{ID: "1", DateTime: "2016-01-01T00:00:00Z"},
+					{ID: "2", DateTime: "2016-01-01T00:00:00Z"},
+				},
+			}
+
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodPost,
+				fmt.Sprintf("/api/s/%s/stat/event", wantSite),
+				&eventQuery{Start: 100, Limit: 2},
+				v,
+			))
+			defer done()
+
+			events, total, err := c.EventsPage(wantSite, 100, 2)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.EventsPage: %v", err)
+			}
+
+			if want, got := 2, len(events); want != got {
+				t.Fatalf("unexpected number of Events:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+
+			if want, got := tt.total, total; want != got {
+				t.Fatalf("unexpected total number of Events:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientEventsSince(t *testing.T) {
+	const wantSite = "default"
+
+	now := time.Now()
+	since := now.Add(-90 * time.Minute)
+
+	v := struct {
+		Events []event `json:"data"`
+	}{
+		Events: []event{
+			{ID: "new", DateTime: now.Add(-time.Minute).Format(time.RFC3339)},
+			{ID: "edge", DateTime: since.Add(time.Second).Format(time.RFC3339)},
+			{ID: "old", DateTime: now.Add(-100 * time.Minute).Format(time.RFC3339)},
+		},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/stat/event", wantSite),
+		&eventQuery{Within: 2, Limit: 3, Sort: "-time"},
+		v,
+	))
+	defer done()
+
+	events, err := c.EventsSince(wantSite, since, 3)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.EventsSince: %v", err)
+	}
+
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+
+	if want, got := []string{"new", "edge"}, ids; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Events:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestEventUnmarshalJSON(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    []byte
+		e    *Event
+		err  error
+	}{
+		{
+			desc: "invalid JSON",
+			b:    []byte(`<>`),
+			err:  errors.New("invalid character"),
+		},
+		{
+			desc: "invalid DateTime",
+			b:    []byte(`{"datetime":"foo"}`),
+			err:  errors.New("parsing time"),
+		},
+		{
+			desc: "invalid user",
+			b:    []byte(`{"datetime":"2016-01-01T00:00:00Z","user":"foo"}`),
+			err:  errors.New("invalid MAC address"),
+		},
+		{
+			desc: "invalid AP",
+			b:    []byte(`{"datetime":"2016-01-01T00:00:00Z","ap":"foo"}`),
+			err:  errors.New("invalid MAC address"),
+		},
+		{
+			desc: "OK no user",
+			b:    []byte(`{"_id":"abcdef1234567890","datetime":"2016-01-01T00:00:00Z","key":"EVT_AD_Login","subsystem":"lan"}`),
+			e: &Event{
+				ID:        "abcdef1234567890",
+				DateTime:  time.Date(2016, time.January, 01, 0, 0, 0, 0, time.UTC),
+				Key:       "EVT_AD_Login",
+				Severity:  SeverityInfo,
+				Subsystem: "lan",
+			},
+		},
+		{
+			desc: "OK critical",
+			b:    []byte(`{"_id":"abcdef1234567890","datetime":"2016-01-01T00:00:00Z","key":"EVT_AP_Lost_Contact","subsystem":"wlan"}`),
+			e: &Event{
+				ID:        "abcdef1234567890",
+				DateTime:  time.Date(2016, time.January, 01, 0, 0, 0, 0, time.UTC),
+				Key:       "EVT_AP_Lost_Contact",
+				Severity:  SeverityCritical,
+				Subsystem: "wlan",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			e := new(Event)
+			err := e.UnmarshalJSON(tt.b)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err != nil {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.e, e; !reflect.DeepEqual(got, want) {
+				t.Fatalf("unexpected Event:\n- want: %+v\n-  got: %+v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientPresenceStream(t *testing.T) {
+	const wantSite = "default"
+	var (
+		wantMAC = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad}
+		at      = time.Date(2016, time.January, 01, 0, 0, 0, 0, time.UTC)
+	)
+
+	old := event{
+		ID:       "1",
+		DateTime: at.Format(time.RFC3339),
+		Key:      "EVT_WU_Connected",
+		User:     wantMAC.String(),
+	}
+
+	// Newest events first, as requested by PresenceStream.
+	polls := [][]event{
+		{old},
+		{
+			{
+				ID:       "4",
+				DateTime: at.Add(3 * time.Second).Format(time.RFC3339),
+				Key:      "EVT_LU_Connected",
+				Hostname: "wired",
+				User:     wantMAC.String(),
+			},
+			{
+				ID:       "3",
+				DateTime: at.Add(2 * time.Second).Format(time.RFC3339),
+				Key:      "EVT_AD_Login",
+			},
+			{
+				ID:       "2",
+				DateTime: at.Add(time.Second).Format(time.RFC3339),
+				Key:      "EVT_WU_Disconnected",
+				Hostname: "wireless",
+				User:     wantMAC.String(),
+			},
+			old,
+		},
+	}
+
+	var i int
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		events := polls[len(polls)-1]
+		if i < len(polls) {
+			events = polls[i]
+		}
+		i++
+
+		v := struct {
+			Events []event `json:"data"`
+		}{
+			Events: events,
+		}
+
+		testHandler(
+			t,
+			http.MethodPost,
+			fmt.Sprintf("/api/s/%s/stat/event", wantSite),
+			&eventQuery{Within: 1, Sort: "-time"},
+			v,
+		)(w, r)
+	})
+	defer done()
+	c.pollInterval = 5 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.PresenceStream(ctx, wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.PresenceStream: %v", err)
+	}
+
+	want := []PresenceEvent{
+		{
+			MAC:       wantMAC,
+			Hostname:  "wireless",
+			Connected: false,
+			At:        at.Add(time.Second),
+		},
+		{
+			MAC:       wantMAC,
+			Hostname:  "wired",
+			Connected: true,
+			At:        at.Add(3 * time.Second),
+		},
+	}
+
+	var got []PresenceEvent
+	for e := range ch {
+		got = append(got, e)
+		if len(got) == len(want) {
+			cancel()
+		}
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected PresenceEvents:\n- want: %+v\n-  got: %+v",
+			want, got)
+	}
+}
+
+func TestClientPresenceStreamPollError(t *testing.T) {
+	const wantSite = "default"
+
+	var (
+		mu    sync.Mutex
+		polls int
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		n := polls
+		mu.Unlock()
+
+		// The session expires after the initial poll.
+		if n > 1 {
+			w.Header().Set("Content-Type", jsonContentType)
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"api.err.LoginRequired"},"data":[]}`))
+			return
+		}
+
+		testHandler(
+			t,
+			http.MethodPost,
+			fmt.Sprintf("/api/s/%s/stat/event", wantSite),
+			&eventQuery{Within: 1, Sort: "-time"},
+			struct {
+				Events []event `json:"data"`
+			}{},
+		)(w, r)
+	})
+	defer done()
+	c.pollInterval = 5 * time.Millisecond
+
+	errC := make(chan error, 1)
+	c.PollError = func(err error) {
+		select {
+		case errC <- err:
+		default:
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.PresenceStream(ctx, wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.PresenceStream: %v", err)
+	}
+
+	if err := <-errC; !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("unexpected poll error:\n- want: %v\n-  got: %v", ErrLoginRequired, err)
+	}
+
+	cancel()
+	for e := range ch {
+		t.Fatalf("unexpected PresenceEvent: %+v", e)
+	}
+}
+
+func TestClientWriteEventsCSV(t *testing.T) {
+	const wantSite = "default"
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var (
+		older = now.Add(-3 * time.Hour)
+		t1    = now.Add(-90 * time.Minute)
+		t2    = now.Add(-30 * time.Minute)
+	)
+
+	// Newest first, as returned by the UniFi Controller.
+	events := []event{
+		{
+			ID:        "3",
+			DateTime:  t2.Format(time.RFC3339),
+			Key:       "EVT_AP_Lost_Contact",
+			Msg:       `AP "lobby" was disconnected`,
+			Subsystem: "wlan",
+			AP:        "ab:ad:1d:ea:ab:ad",
+		},
+		{
+			ID:        "2",
+			DateTime:  t1.Format(time.RFC3339),
+			Key:       "EVT_WU_Connected",
+			Msg:       "User connected, roamed",
+			Subsystem: "wlan",
+			AP:        "ab:ad:1d:ea:ab:ad",
+			User:      "de:ad:be:ef:de:ad",
+		},
+		{
+			ID:        "1",
+			DateTime:  older.Format(time.RFC3339),
+			Key:       "EVT_AD_Login",
+			Subsystem: "lan",
+		},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/stat/event", wantSite),
+		&eventQuery{Within: 2},
+		struct {
+			Events []event `json:"data"`
+		}{Events: events},
+	))
+	defer done()
+
+	var buf bytes.Buffer
+	if err := c.WriteEventsCSV(wantSite, &buf, 2*time.Hour); err != nil {
+		t.Fatalf("unexpected error from Client.WriteEventsCSV: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"time,subsystem,key,ap,client,message",
+		t1.Format(time.RFC3339) + `,wlan,EVT_WU_Connected,ab:ad:1d:ea:ab:ad,de:ad:be:ef:de:ad,"User connected, roamed"`,
+		t2.Format(time.RFC3339) + `,wlan,EVT_AP_Lost_Contact,ab:ad:1d:ea:ab:ad,,"AP ""lobby"" was disconnected"`,
+		"",
+	}, "\n")
+
+	if got := buf.String(); want != got {
+		t.Fatalf("unexpected CSV:\n- want: %v\n-  got: %v", want, got)
+	}
+}
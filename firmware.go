@@ -0,0 +1,96 @@
+package unifi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AvailableFirmware returns a map of Device model names to the newest
+// firmware version which the UniFi Controller knows to be available for each
+// model, regardless of the firmware installed on any Device.
+//
+// The versions are retrieved by sending the "list-available" command to the
+// firmware manager of the default site, at /api/s/default/cmd/firmware, as
+// the UniFi Controller's firmware cache is shared by all sites.
+//
+// Not all UniFi Controller versions expose their firmware cache.  If the
+// UniFi Controller does not support the command, or reports no firmware at
+// all, an error wrapping ErrUnsupported is returned rather than an empty map
+// which would suggest that every Device is up to date.
+func (c *Client) AvailableFirmware() (map[string]string, error) {
+	var v struct {
+		Firmware []struct {
+			Device  string `json:"device"`
+			Version string `json:"version"`
+		} `json:"data"`
+	}
+
+	req, err := c.newRequest(
+		http.MethodPost,
+		"/api/s/default/cmd/firmware",
+		&firmwareCommand{Command: "list-available"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req, &v); err != nil {
+		if isUnsupported(err) {
+			return nil, fmt.Errorf("failed to list available firmware: %w: %w", ErrUnsupported, err)
+		}
+
+		return nil, err
+	}
+
+	if len(v.Firmware) == 0 {
+		return nil, fmt.Errorf("controller reported no available firmware: %w", ErrUnsupported)
+	}
+
+	// The UniFi Controller may know of several versions for a model, so keep
+	// only the newest.
+	latest := make(map[string]string, len(v.Firmware))
+	for _, f := range v.Firmware {
+		if f.Device == "" || f.Version == "" {
+			continue
+		}
+
+		if cur, ok := latest[f.Device]; !ok || compareVersions(f.Version, cur) > 0 {
+			latest[f.Device] = f.Version
+		}
+	}
+
+	return latest, nil
+}
+
+// A firmwareCommand is a command sent to the UniFi Controller's firmware
+// manager.
+type firmwareCommand struct {
+	Command string `json:"cmd"`
+}
+
+// compareVersions compares the dotted firmware versions a and b, returning a
+// negative number if a is older than b, a positive number if a is newer than
+// b, and zero if they are equal.  Numeric components are compared by value,
+// and any others are compared lexically.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+		if aerr == nil && berr == nil {
+			if an != bn {
+				return an - bn
+			}
+
+			continue
+		}
+
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(as) - len(bs)
+}
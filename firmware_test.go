@@ -0,0 +1,104 @@
+package unifi
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientAvailableFirmware(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		firmware []map[string]interface{}
+		want     map[string]string
+		err      error
+	}{
+		{
+			desc: "empty",
+			err:  ErrUnsupported,
+		},
+		{
+			desc: "OK",
+			firmware: []map[string]interface{}{
+				{"device": "U7PG2", "version": "4.3.28.11361"},
+				{"device": "U7PG2", "version": "4.3.9.10948"},
+				{"device": "U7PG2", "version": "4.3.13.11253"},
+				{"device": "US24P250", "version": "4.3.13.11253"},
+				{"device": "UGW3", "version": ""},
+			},
+			want: map[string]string{
+				"U7PG2":    "4.3.28.11361",
+				"US24P250": "4.3.13.11253",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodPost,
+				"/api/s/default/cmd/firmware",
+				map[string]string{"cmd": "list-available"},
+				map[string]interface{}{"data": tt.firmware},
+			))
+			defer done()
+
+			firmware, err := c.AvailableFirmware()
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if err != nil {
+				return
+			}
+
+			if want, got := tt.want, firmware; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected firmware:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientAvailableFirmwareUnsupported(t *testing.T) {
+	const wantMsg = "api.err.UnknownCommand"
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"` + wantMsg + `"},"data":[]}`))
+	})
+	defer done()
+
+	_, err := c.AvailableFirmware()
+	if err == nil || !strings.Contains(err.Error(), wantMsg) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", wantMsg, err)
+	}
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrUnsupported, err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		want int
+	}{
+		{a: "4.3.28.11361", b: "4.3.28.11361", want: 0},
+		{a: "4.3.28.11361", b: "4.3.9.10948", want: 1},
+		{a: "4.0.80", b: "4.0.80.10875", want: -1},
+		{a: "5.43.23.12533", b: "5.43.23.12533-beta", want: -1},
+	}
+
+	for _, tt := range tests {
+		got := compareVersions(tt.a, tt.b)
+		switch {
+		case tt.want == 0 && got != 0,
+			tt.want > 0 && got <= 0,
+			tt.want < 0 && got >= 0:
+			t.Fatalf("unexpected comparison of %q and %q:\n- want: %v\n-  got: %v",
+				tt.a, tt.b, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/mdlayher/unifi
+
+go 1.20
@@ -0,0 +1,188 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sort"
+	"time"
+)
+
+// Guests returns all of the Guest authorizations for a specified site name,
+// including those granted by AuthorizeGuest and those which have expired.
+func (c *Client) Guests(siteName string) ([]*Guest, error) {
+	return getList[Guest](context.Background(), c, siteName, "stat/guest")
+}
+
+// GuestAuthOptions specifies optional limits for a guest authorization.  Zero
+// fields are omitted, so that the UniFi Controller applies the defaults
+// configured for the site.
+type GuestAuthOptions struct {
+	// Minutes is the duration of the authorization, in minutes.
+	Minutes int
+
+	// UpBytes and DownBytes limit the rate at which the guest may upload
+	// and download, in kilobits per second.
+	UpBytes   int
+	DownBytes int
+
+	// ByteQuota limits the total amount of data the guest may transfer, in
+	// megabytes.
+	ByteQuota int
+}
+
+// AuthorizeGuest grants the client with the specified MAC address access to
+// the guest network of a specified site name, as if it had authenticated with
+// the guest portal.  If opts is nil, the site's defaults are used.  If the
+// UniFi Controller rejects the command, its error is returned.
+func (c *Client) AuthorizeGuest(siteName string, mac net.HardwareAddr, opts *GuestAuthOptions) error {
+	const cmd = "authorize-guest"
+	if err := checkMAC("station", cmd, mac); err != nil {
+		return err
+	}
+
+	if opts == nil {
+		opts = &GuestAuthOptions{}
+	}
+
+	return c.stamgr(siteName, &guestCommand{
+		Command: cmd,
+		MAC:     mac.String(),
+		Minutes: opts.Minutes,
+		Up:      opts.UpBytes,
+		Down:    opts.DownBytes,
+		Bytes:   opts.ByteQuota,
+	})
+}
+
+// UnauthorizeGuest revokes the guest network access of the client with the
+// specified MAC address on a specified site name.  If the UniFi Controller
+// rejects the command, its error is returned.
+func (c *Client) UnauthorizeGuest(siteName string, mac net.HardwareAddr) error {
+	return c.stationMACCommand(siteName, "unauthorize-guest", mac)
+}
+
+// A guestCommand is a station manager command which authorizes a guest.
+type guestCommand struct {
+	Command string `json:"cmd"`
+	MAC     string `json:"mac"`
+	Minutes int    `json:"minutes,omitempty"`
+	Up      int    `json:"up,omitempty"`
+	Down    int    `json:"down,omitempty"`
+	Bytes   int    `json:"bytes,omitempty"`
+}
+
+// ExpiringGuests returns the Guest authorizations for a specified site name
+// which expire within the specified duration from now, sorted so that the
+// soonest to expire is first.  Authorizations which have already expired or
+// which never expire are not included.
+func (c *Client) ExpiringGuests(siteName string, within time.Duration) ([]*Guest, error) {
+	guests, err := c.Guests(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deadline := now.Add(within)
+
+	expiring := make([]*Guest, 0, len(guests))
+	for _, g := range guests {
+		if g.Expired || g.End.IsZero() || !g.End.After(now) || g.End.After(deadline) {
+			continue
+		}
+
+		expiring = append(expiring, g)
+	}
+
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].End.Before(expiring[j].End)
+	})
+
+	return expiring, nil
+}
+
+// A Guest is an authorization which grants a client access to a site's
+// guest network, such as by redeeming a Voucher on a guest portal.
+type Guest struct {
+	ID           string
+	AuthorizedBy string // Method of authorization, such as "voucher"
+	MAC          net.HardwareAddr
+	SiteID       string
+	Expired      bool
+
+	// Start and End are the times at which the authorization began and
+	// ends.  End is zero if the authorization never expires.
+	Start time.Time
+	End   time.Time
+
+	// Duration is the length of the authorization granted, which is zero
+	// if it never expires.
+	Duration time.Duration
+
+	// ReceiveBytes and TransmitBytes are the amount of data transferred by
+	// the guest during the authorization.
+	ReceiveBytes  int64
+	TransmitBytes int64
+
+	// VoucherID is the ID of the Voucher redeemed to obtain the
+	// authorization, if it was authorized by a Voucher.
+	VoucherID string
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a Guest is unmarshaled.
+func (*Guest) raw() interface{} { return new(guest) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of a Guest.
+func (g *Guest) UnmarshalJSON(b []byte) error {
+	var gu guest
+	if err := json.Unmarshal(b, &gu); err != nil {
+		return err
+	}
+
+	mac, err := net.ParseMAC(gu.MAC)
+	if err != nil {
+		return err
+	}
+
+	var end time.Time
+	if gu.End != 0 {
+		end = time.Unix(gu.End, 0)
+	}
+
+	*g = Guest{
+		ID:           gu.ID,
+		AuthorizedBy: gu.AuthorizedBy,
+		MAC:          mac,
+		SiteID:       gu.SiteID,
+		Expired:      gu.Expired,
+		Start:        time.Unix(gu.Start, 0),
+		End:          end,
+
+		Duration: time.Duration(gu.Duration) * time.Minute,
+
+		ReceiveBytes:  int64(gu.RxBytes),
+		TransmitBytes: int64(gu.TxBytes),
+
+		VoucherID: gu.VoucherID,
+	}
+
+	return nil
+}
+
+// A guest is the raw structure of a Guest returned from the UniFi Controller
+// API.
+type guest struct {
+	ID           string  `json:"_id"`
+	AuthorizedBy string  `json:"authorized_by"`
+	Duration     int64   `json:"duration"`
+	End          int64   `json:"end"`
+	Expired      bool    `json:"expired"`
+	MAC          string  `json:"mac"`
+	RxBytes      float64 `json:"rx_bytes"`
+	SiteID       string  `json:"site_id"`
+	Start        int64   `json:"start"`
+	TxBytes      float64 `json:"tx_bytes"`
+	VoucherCode  string  `json:"voucher_code"`
+	VoucherID    string  `json:"voucher_id"`
+}
@@ -0,0 +1,174 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClientGuests(t *testing.T) {
+	const wantSite = "default"
+
+	wantGuest := &Guest{
+		ID:           "abcdef1234567890",
+		AuthorizedBy: "voucher",
+		MAC:          net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+		SiteID:       "somesite",
+		Start:        time.Unix(1451606400, 0),
+		End:          time.Unix(1451610000, 0),
+
+		Duration: time.Hour,
+
+		ReceiveBytes:  5e9,
+		TransmitBytes: 1024,
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/stat/guest", wantSite),
+		nil,
+		struct {
+			Guests []guest `json:"data"`
+		}{Guests: []guest{{
+			ID:           "abcdef1234567890",
+			AuthorizedBy: "voucher",
+			Duration:     60,
+			End:          1451610000,
+			MAC:          "de:ad:be:ef:de:ad",
+			RxBytes:      5e9,
+			SiteID:       "somesite",
+			Start:        1451606400,
+			TxBytes:      1024,
+		}}},
+	))
+	defer done()
+
+	guests, err := c.Guests(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.Guests: %v", err)
+	}
+
+	if want, got := []*Guest{wantGuest}, guests; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Guests:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientExpiringGuests(t *testing.T) {
+	const wantSite = "default"
+
+	now := time.Now().Unix()
+	guests := []guest{
+		{ID: "later", MAC: "de:ad:be:ef:de:01", End: now + 1800},
+		{ID: "soon", MAC: "de:ad:be:ef:de:02", End: now + 300},
+		{ID: "outside", MAC: "de:ad:be:ef:de:03", End: now + 7200},
+		{ID: "unlimited", MAC: "de:ad:be:ef:de:04"},
+		{ID: "expired", MAC: "de:ad:be:ef:de:05", End: now + 600, Expired: true},
+		{ID: "past", MAC: "de:ad:be:ef:de:06", End: now - 60},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/stat/guest", wantSite),
+		nil,
+		struct {
+			Guests []guest `json:"data"`
+		}{Guests: guests},
+	))
+	defer done()
+
+	expiring, err := c.ExpiringGuests(wantSite, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.ExpiringGuests: %v", err)
+	}
+
+	ids := make([]string, 0, len(expiring))
+	for _, g := range expiring {
+		ids = append(ids, g.ID)
+	}
+
+	if want, got := []string{"soon", "later"}, ids; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected expiring Guests:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientAuthorizeGuest(t *testing.T) {
+	const wantSite = "default"
+
+	mac := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad}
+
+	var tests = []struct {
+		desc string
+		opts *GuestAuthOptions
+		body string
+	}{
+		{
+			desc: "defaults",
+			body: `{"cmd":"authorize-guest","mac":"de:ad:be:ef:de:ad"}`,
+		},
+		{
+			desc: "some limits",
+			opts: &GuestAuthOptions{
+				Minutes: 60,
+				UpBytes: 512,
+			},
+			body: `{"cmd":"authorize-guest","mac":"de:ad:be:ef:de:ad","minutes":60,"up":512}`,
+		},
+		{
+			desc: "all limits",
+			opts: &GuestAuthOptions{
+				Minutes:   1440,
+				UpBytes:   512,
+				DownBytes: 2048,
+				ByteQuota: 100,
+			},
+			body: `{"cmd":"authorize-guest","mac":"de:ad:be:ef:de:ad","minutes":1440,"up":512,"down":2048,"bytes":100}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodPost,
+				fmt.Sprintf("/api/s/%s/cmd/stamgr", wantSite),
+				json.RawMessage(tt.body),
+				nil,
+			))
+			defer done()
+
+			if err := c.AuthorizeGuest(wantSite, mac, tt.opts); err != nil {
+				t.Fatalf("unexpected error from Client.AuthorizeGuest: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientUnauthorizeGuest(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/stamgr", wantSite),
+		map[string]string{
+			"cmd": "unauthorize-guest",
+			"mac": "de:ad:be:ef:de:ad",
+		},
+		nil,
+	))
+	defer done()
+
+	if err := c.UnauthorizeGuest(wantSite, net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad}); err != nil {
+		t.Fatalf("unexpected error from Client.UnauthorizeGuest: %v", err)
+	}
+
+	if err := c.AuthorizeGuest(wantSite, nil, nil); err == nil {
+		t.Fatal("expected an error for an invalid MAC address")
+	}
+}
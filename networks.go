@@ -0,0 +1,88 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+)
+
+// Networks returns all of the Networks for a specified site name.
+func (c *Client) Networks(siteName string) ([]*Network, error) {
+	return getList[Network](context.Background(), c, siteName, "rest/networkconf")
+}
+
+// A Network is a network configured on a site, such as a LAN, VLAN, or WAN.
+type Network struct {
+	ID          string
+	DHCPEnabled bool
+	Name        string
+	Purpose     string
+	SiteID      string
+	Subnet      string // Gateway address and prefix, such as "192.168.1.1/24"
+	VLAN        int
+
+	// DNSServers, Gateway, LeaseTime, and DomainName are the options the
+	// Network's DHCP server provides to clients.  Unset options are empty.
+	DNSServers []net.IP
+	Gateway    net.IP
+	LeaseTime  time.Duration
+	DomainName string
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a Network is unmarshaled.
+func (*Network) raw() interface{} { return new(network) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of a Network.
+func (n *Network) UnmarshalJSON(b []byte) error {
+	var nw network
+	if err := json.Unmarshal(b, &nw); err != nil {
+		return err
+	}
+
+	// The UniFi Controller stores up to four DNS servers in numbered fields,
+	// leaving unused fields empty.
+	var dns []net.IP
+	for _, s := range []string{nw.DHCPDDNS1, nw.DHCPDDNS2, nw.DHCPDDNS3, nw.DHCPDDNS4} {
+		if ip := net.ParseIP(s); ip != nil {
+			dns = append(dns, ip)
+		}
+	}
+
+	*n = Network{
+		ID:          nw.ID,
+		DHCPEnabled: nw.DHCPDEnabled,
+		Name:        nw.Name,
+		Purpose:     nw.Purpose,
+		SiteID:      nw.SiteID,
+		Subnet:      nw.IPSubnet,
+		VLAN:        int(nw.VLAN),
+
+		DNSServers: dns,
+		Gateway:    net.ParseIP(nw.DHCPDGateway),
+		LeaseTime:  time.Duration(nw.DHCPDLeaseTime) * time.Second,
+		DomainName: nw.DomainName,
+	}
+
+	return nil
+}
+
+// A network is the raw structure of a Network returned from the UniFi
+// Controller API.
+type network struct {
+	ID             string  `json:"_id"`
+	DHCPDDNS1      string  `json:"dhcpd_dns_1"`
+	DHCPDDNS2      string  `json:"dhcpd_dns_2"`
+	DHCPDDNS3      string  `json:"dhcpd_dns_3"`
+	DHCPDDNS4      string  `json:"dhcpd_dns_4"`
+	DHCPDEnabled   bool    `json:"dhcpd_enabled"`
+	DHCPDGateway   string  `json:"dhcpd_gateway"`
+	DHCPDLeaseTime flexInt `json:"dhcpd_leasetime"`
+	DomainName     string  `json:"domain_name"`
+	IPSubnet       string  `json:"ip_subnet"`
+	Name           string  `json:"name"`
+	Purpose        string  `json:"purpose"`
+	SiteID         string  `json:"site_id"`
+	VLAN           flexInt `json:"vlan"`
+}
@@ -0,0 +1,102 @@
+package unifi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClientNetworks(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/rest/networkconf", wantSite),
+		nil,
+		struct {
+			Networks []network `json:"data"`
+		}{Networks: []network{{ID: "abcdef1234567890", Name: "LAN"}}},
+	))
+	defer done()
+
+	networks, err := c.Networks(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.Networks: %v", err)
+	}
+
+	if want, got := 1, len(networks); want != got {
+		t.Fatalf("unexpected number of Networks:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+}
+
+func TestNetworkUnmarshalJSON(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    string
+		n    *Network
+	}{
+		{
+			desc: "no DHCP options",
+			b:    `{"_id":"abcdef1234567890","name":"WAN","purpose":"wan"}`,
+			n: &Network{
+				ID:      "abcdef1234567890",
+				Name:    "WAN",
+				Purpose: "wan",
+			},
+		},
+		{
+			desc: "OK",
+			b: `{
+				"_id": "abcdef1234567890",
+				"dhcpd_dns_1": "192.168.10.1",
+				"dhcpd_dns_2": "",
+				"dhcpd_dns_3": "2001:db8::53",
+				"dhcpd_enabled": true,
+				"dhcpd_gateway": "192.168.10.1",
+				"dhcpd_leasetime": "86400",
+				"domain_name": "example.com",
+				"ip_subnet": "192.168.10.1/24",
+				"name": "IoT",
+				"purpose": "corporate",
+				"site_id": "somesite",
+				"vlan": "10"
+			}`,
+			n: &Network{
+				ID:          "abcdef1234567890",
+				DHCPEnabled: true,
+				Name:        "IoT",
+				Purpose:     "corporate",
+				SiteID:      "somesite",
+				Subnet:      "192.168.10.1/24",
+				VLAN:        10,
+
+				DNSServers: []net.IP{
+					net.IPv4(192, 168, 10, 1),
+					net.ParseIP("2001:db8::53"),
+				},
+				Gateway:    net.IPv4(192, 168, 10, 1),
+				LeaseTime:  24 * time.Hour,
+				DomainName: "example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			n := new(Network)
+			if err := n.UnmarshalJSON([]byte(tt.b)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.n, n; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected Network:\n- want: %+v\n-  got: %+v",
+					want, got)
+			}
+		})
+	}
+}
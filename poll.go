@@ -0,0 +1,294 @@
+package unifi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// A SiteSnapshot is a point-in-time view of the Devices and Stations on a
+// site.
+type SiteSnapshot struct {
+	Site     string
+	Time     time.Time
+	Devices  []*Device
+	Stations []*Station
+}
+
+// Snapshot retrieves a SiteSnapshot for a specified site name.  The Devices
+// and Stations are retrieved concurrently.  If either cannot be retrieved, an
+// error is returned.
+func (c *Client) Snapshot(ctx context.Context, siteName string) (*SiteSnapshot, error) {
+	s := &SiteSnapshot{
+		Site: siteName,
+		Time: time.Now(),
+	}
+
+	var (
+		wg             sync.WaitGroup
+		devErr, staErr error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.Devices, devErr = c.devices(ctx, siteName)
+	}()
+	go func() {
+		defer wg.Done()
+		s.Stations, staErr = c.stations(ctx, siteName)
+	}()
+	wg.Wait()
+
+	if devErr != nil {
+		return nil, devErr
+	}
+	if staErr != nil {
+		return nil, staErr
+	}
+
+	return s, nil
+}
+
+// Poll retrieves a SiteSnapshot for a specified site name immediately and
+// then once per interval, calling fn with each SiteSnapshot.
+//
+// Polling is aligned to interval rather than to the completion of fn, so a
+// slow fn does not cause polling to drift; if fn takes longer than interval,
+// the cycles which were missed are skipped.  Errors retrieving a SiteSnapshot
+// also cause a cycle to be skipped, and are reported to the Client's
+// PollError function, if set.
+//
+// Poll blocks until ctx is canceled, and then returns its error.  If the
+// Client is closed, Poll returns ErrClosed.  interval must be greater than
+// zero.
+func (c *Client) Poll(ctx context.Context, siteName string, interval time.Duration, fn func(s *SiteSnapshot)) error {
+	if interval <= 0 {
+		return errors.New("polling interval must be greater than zero")
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		s, err := c.Snapshot(ctx, siteName)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err == ErrClosed:
+			return err
+		case err != nil:
+			if c.PollError != nil {
+				c.PollError(err)
+			}
+		default:
+			fn(s)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.closed:
+			return ErrClosed
+		case <-t.C:
+		}
+	}
+}
+
+// A DeviceChangeType indicates how a Device changed between two polls.
+type DeviceChangeType int
+
+// List of possible DeviceChangeType values.
+const (
+	DeviceAdded DeviceChangeType = iota
+	DeviceRemoved
+	DeviceModified
+)
+
+// String returns the string representation of a DeviceChangeType.
+func (t DeviceChangeType) String() string {
+	switch t {
+	case DeviceAdded:
+		return "added"
+	case DeviceRemoved:
+		return "removed"
+	case DeviceModified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// A DeviceChange indicates that a Device was added to, removed from, or
+// modified on a site.  For removed Devices, Device is the Device as it was
+// last observed.
+type DeviceChange struct {
+	Type   DeviceChangeType
+	Device *Device
+}
+
+// WatchDevices polls the Devices for a specified site name once per interval,
+// and emits a DeviceChange on the returned channel for each Device which was
+// added, removed, or modified since the previous poll.  Devices are matched
+// between polls by ID.  The Devices observed when WatchDevices is called are
+// the starting point for comparison, and are not emitted.
+//
+// Only meaningful changes are reported as modifications: a Device's name,
+// model, firmware version, adoption and connection state, and IP address.
+// Changes in statistics, uptime, and other volatile values are ignored.
+//
+// If Devices cannot be retrieved while polling, that poll is skipped and the
+// error is reported to the Client's PollError function, if set.  The
+// channel is closed when ctx is canceled or the Client is closed.
+func (c *Client) WatchDevices(ctx context.Context, siteName string, interval time.Duration) (<-chan DeviceChange, error) {
+	if interval <= 0 {
+		return nil, errors.New("polling interval must be greater than zero")
+	}
+
+	devices, err := c.devices(ctx, siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan DeviceChange)
+	go func() {
+		defer close(ch)
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		prev := devices
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.closed:
+				return
+			case <-t.C:
+			}
+
+			devices, err := c.devices(ctx, siteName)
+			if err != nil {
+				if ctx.Err() == nil && err != ErrClosed && c.PollError != nil {
+					c.PollError(err)
+				}
+				continue
+			}
+
+			for _, dc := range diffDevices(prev, devices) {
+				select {
+				case <-ctx.Done():
+					return
+				case <-c.closed:
+					return
+				case ch <- dc:
+				}
+			}
+
+			prev = devices
+		}
+	}()
+
+	return ch, nil
+}
+
+// diffDevices returns the DeviceChanges between the prev and next Devices.
+// Added and modified Devices are reported in the order of next, followed by
+// removed Devices in the order of prev.
+func diffDevices(prev, next []*Device) []DeviceChange {
+	before := make(map[string]*Device, len(prev))
+	for _, d := range prev {
+		before[d.ID] = d
+	}
+
+	var changes []DeviceChange
+	after := make(map[string]struct{}, len(next))
+	for _, d := range next {
+		after[d.ID] = struct{}{}
+
+		p, ok := before[d.ID]
+		switch {
+		case !ok:
+			changes = append(changes, DeviceChange{Type: DeviceAdded, Device: d})
+		case deviceModified(p, d):
+			changes = append(changes, DeviceChange{Type: DeviceModified, Device: d})
+		}
+	}
+
+	for _, d := range prev {
+		if _, ok := after[d.ID]; !ok {
+			changes = append(changes, DeviceChange{Type: DeviceRemoved, Device: d})
+		}
+	}
+
+	return changes
+}
+
+// deviceModified reports whether a Device has changed in a meaningful way
+// between observations a and b.
+func deviceModified(a, b *Device) bool {
+	return a.Name != b.Name ||
+		a.Model != b.Model ||
+		a.Version != b.Version ||
+		a.Adopted != b.Adopted ||
+		a.State != b.State ||
+		!a.InformIP.Equal(b.InformIP)
+}
+
+// maxBackoff is the maximum multiple of its initial interval which PollUntil
+// waits between calls.
+const maxBackoff = 8
+
+// PollUntil calls fn until it reports that it is done, which is useful for
+// waiting on the UniFi Controller to complete an asynchronous operation.
+//
+// fn is called immediately, and then again after waiting for interval.  The
+// wait doubles after each call, up to eight times interval, and is extended
+// by a random jitter of up to one fifth so that many concurrent pollers do
+// not act in lockstep.
+//
+// If fn returns an error, polling stops and that error is returned.  If
+// timeout is greater than zero and elapses before fn is done, ErrTimeout is
+// returned.  If ctx is canceled, its error is returned.
+func PollUntil(ctx context.Context, interval, timeout time.Duration, fn func() (done bool, err error)) error {
+	if interval <= 0 {
+		return errors.New("polling interval must be greater than zero")
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		deadline = t.C
+	}
+
+	wait := interval
+	for {
+		done, err := fn()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		t := time.NewTimer(jitter(wait))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-deadline:
+			t.Stop()
+			return ErrTimeout
+		case <-t.C:
+		}
+
+		if wait < maxBackoff*interval {
+			wait *= 2
+		}
+		if wait > maxBackoff*interval {
+			wait = maxBackoff * interval
+		}
+	}
+}
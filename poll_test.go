@@ -0,0 +1,340 @@
+package unifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientPoll(t *testing.T) {
+	const wantSite = "default"
+
+	var (
+		mu       sync.Mutex
+		stations int
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/s/%s/stat/device", wantSite):
+			testHandler(t, http.MethodGet, r.URL.Path, nil, struct {
+				Devices []device `json:"data"`
+			}{
+				Devices: []device{{InformIP: "192.168.1.1"}},
+			})(w, r)
+		case fmt.Sprintf("/api/s/%s/stat/sta", wantSite):
+			mu.Lock()
+			stations++
+			n := stations
+			mu.Unlock()
+
+			// Fail the second cycle so that it is skipped.
+			if n == 2 {
+				w.Header().Set("Content-Type", jsonContentType)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			testHandler(t, http.MethodGet, r.URL.Path, nil, struct {
+				Stations []station `json:"data"`
+			}{
+				Stations: []station{{Mac: "de:ad:be:ef:de:ad", IsWired: true}},
+			})(w, r)
+		default:
+			t.Fatalf("unexpected URL path: %v", r.URL.Path)
+		}
+	})
+	defer done()
+
+	var errs int
+	c.PollError = func(err error) { errs++ }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var snapshots []*SiteSnapshot
+	err := c.Poll(ctx, wantSite, 5*time.Millisecond, func(s *SiteSnapshot) {
+		snapshots = append(snapshots, s)
+		if len(snapshots) == 2 {
+			cancel()
+		}
+	})
+	if want, got := context.Canceled, err; want != got {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	if want, got := 1, errs; want != got {
+		t.Fatalf("unexpected number of poll errors:\n- want: %d\n-  got: %d", want, got)
+	}
+
+	for _, s := range snapshots {
+		if s.Site != wantSite || len(s.Devices) != 1 || len(s.Stations) != 1 {
+			t.Fatalf("unexpected SiteSnapshot: %+v", s)
+		}
+	}
+}
+
+func TestClientPollClosed(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request: %v", r.URL.Path)
+	})
+	defer done()
+
+	_ = c.Close()
+
+	err := c.Poll(context.Background(), "default", time.Second, func(*SiteSnapshot) {
+		t.Fatal("unexpected SiteSnapshot")
+	})
+	if want, got := ErrClosed, err; want != got {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientPollBadInterval(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request: %v", r.URL.Path)
+	})
+	defer done()
+
+	err := c.Poll(context.Background(), "default", 0, func(*SiteSnapshot) {
+		t.Fatal("unexpected SiteSnapshot")
+	})
+	if want, got := "interval must be greater than zero", errStr(err); !strings.Contains(got, want) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientWatchDevices(t *testing.T) {
+	const wantSite = "default"
+
+	dev := func(id, name string, uptime int) device {
+		return device{
+			ID:       id,
+			InformIP: "192.168.1.1",
+			Name:     name,
+			Uptime:   uptime,
+		}
+	}
+
+	polls := [][]device{
+		{dev("a", "ap", 1), dev("b", "switch", 1)},
+		// Uptime changes are ignored.
+		{dev("a", "ap", 2), dev("b", "core-switch", 2), dev("c", "gateway", 2)},
+		{dev("a", "ap", 3), dev("c", "gateway", 3)},
+	}
+
+	var i int
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		devices := polls[len(polls)-1]
+		if i < len(polls) {
+			devices = polls[i]
+		}
+		i++
+
+		testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/stat/device", wantSite), nil,
+			struct {
+				Devices []device `json:"data"`
+			}{Devices: devices},
+		)(w, r)
+	})
+	defer done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.WatchDevices(ctx, wantSite, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.WatchDevices: %v", err)
+	}
+
+	type change struct {
+		Type DeviceChangeType
+		ID   string
+		Name string
+	}
+
+	want := []change{
+		{Type: DeviceModified, ID: "b", Name: "core-switch"},
+		{Type: DeviceAdded, ID: "c", Name: "gateway"},
+		{Type: DeviceRemoved, ID: "b", Name: "core-switch"},
+	}
+
+	var got []change
+	for dc := range ch {
+		got = append(got, change{Type: dc.Type, ID: dc.Device.ID, Name: dc.Device.Name})
+		if len(got) == len(want) {
+			cancel()
+		}
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected DeviceChanges:\n- want: %+v\n-  got: %+v",
+			want, got)
+	}
+}
+
+func TestClientWatchDevicesPollError(t *testing.T) {
+	const wantSite = "default"
+
+	var (
+		mu    sync.Mutex
+		polls int
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		n := polls
+		mu.Unlock()
+
+		// Only the initial poll succeeds.
+		if n > 1 {
+			w.Header().Set("Content-Type", jsonContentType)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/stat/device", wantSite), nil,
+			struct {
+				Devices []device `json:"data"`
+			}{
+				Devices: []device{{ID: "a", InformIP: "192.168.1.1"}},
+			},
+		)(w, r)
+	})
+	defer done()
+
+	errC := make(chan error, 1)
+	c.PollError = func(err error) {
+		select {
+		case errC <- err:
+		default:
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.WatchDevices(ctx, wantSite, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.WatchDevices: %v", err)
+	}
+
+	var apiErr *APIError
+	if err := <-errC; !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("unexpected poll error: %v", err)
+	}
+
+	cancel()
+	for dc := range ch {
+		t.Fatalf("unexpected DeviceChange: %+v", dc)
+	}
+}
+
+func TestClientWatchDevicesBadInterval(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request: %v", r.URL.Path)
+	})
+	defer done()
+
+	ch, err := c.WatchDevices(context.Background(), "default", 0)
+	if want, got := "interval must be greater than zero", errStr(err); !strings.Contains(got, want) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+	}
+	if ch != nil {
+		t.Fatal("unexpected non-nil DeviceChange channel")
+	}
+}
+
+func TestPollUntil(t *testing.T) {
+	errFail := errors.New("fail")
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		cancel  bool
+		fn      func(n int) (bool, error)
+		calls   int
+		err     error
+	}{
+		{
+			name: "done",
+			fn: func(n int) (bool, error) {
+				return n == 3, nil
+			},
+			calls: 3,
+		},
+		{
+			name: "error",
+			fn: func(n int) (bool, error) {
+				if n == 2 {
+					return false, errFail
+				}
+
+				return false, nil
+			},
+			calls: 2,
+			err:   errFail,
+		},
+		{
+			name:    "timeout",
+			timeout: 20 * time.Millisecond,
+			fn: func(n int) (bool, error) {
+				return false, nil
+			},
+			err: ErrTimeout,
+		},
+		{
+			name:   "canceled",
+			cancel: true,
+			fn: func(n int) (bool, error) {
+				return false, nil
+			},
+			calls: 1,
+			err:   context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			var calls int
+			err := PollUntil(ctx, time.Millisecond, tt.timeout, func() (bool, error) {
+				calls++
+				return tt.fn(calls)
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", tt.err, err)
+			}
+
+			if tt.calls == 0 {
+				return
+			}
+
+			if want, got := tt.calls, calls; want != got {
+				t.Fatalf("unexpected number of calls:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestPollUntilBadInterval(t *testing.T) {
+	err := PollUntil(context.Background(), 0, 0, func() (bool, error) {
+		t.Fatal("fn should not be called")
+		return false, nil
+	})
+	if err == nil {
+		t.Fatal("expected an error, but none occurred")
+	}
+}
@@ -0,0 +1,55 @@
+package unifi
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// A limiter is a token bucket rate limiter.  The bucket holds at most one
+// second's worth of tokens, and at least one token.
+type limiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// wait blocks until a token is available at the specified rate in tokens per
+// second, or until ctx is canceled.
+func (l *limiter) wait(ctx context.Context, rate float64) error {
+	burst := math.Max(1, rate)
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.last.IsZero() {
+		l.tokens = burst
+	} else {
+		l.tokens = math.Min(burst, l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+	l.last = now
+
+	// Reserve a token now; a negative balance indicates how long this caller
+	// must wait for its reservation to be filled.
+	l.tokens--
+	delay := time.Duration(-l.tokens / rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Return the unused reservation to the bucket.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package unifi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	var n int
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n++
+		w.Header().Set("Content-Type", jsonContentType)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	defer done()
+
+	// Allow a single request, and then no more for the remainder of the test.
+	c.RateLimit = 0.001
+
+	do := func(ctx context.Context) error {
+		req, err := c.newRequest(http.MethodGet, "/", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = c.do(req.WithContext(ctx), nil)
+		return err
+	}
+
+	if err := do(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if want, got := context.DeadlineExceeded, do(ctx); want != got {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	if want, got := 1, n; want != got {
+		t.Fatalf("unexpected number of requests:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	const rate = 100
+
+	var l limiter
+
+	start := time.Now()
+	for i := 0; i < 2*rate; i++ {
+		if err := l.wait(context.Background(), rate); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The first second's worth of tokens are available immediately, and the
+	// remainder are paced at the specified rate.
+	if d := time.Since(start); d < 900*time.Millisecond {
+		t.Fatalf("requests were not rate limited: took %v", d)
+	}
+}
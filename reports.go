@@ -0,0 +1,199 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// A StatInterval is the granularity of a historical statistics report.
+type StatInterval string
+
+// List of possible StatInterval values.
+const (
+	IntervalFiveMinutes StatInterval = "5minutes"
+	IntervalHourly      StatInterval = "hourly"
+	IntervalDaily       StatInterval = "daily"
+)
+
+// UserStats returns historical network activity statistics for the client
+// with the specified MAC address on a specified site name, at the specified
+// interval between start and end.  If the UniFi Controller has no data for
+// the client within that window, an empty slice is returned.
+func (c *Client) UserStats(siteName string, mac string, interval StatInterval, start, end time.Time) ([]*UserStat, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	var v struct {
+		Stats []*UserStat `json:"data"`
+	}
+
+	req, err := c.newRequest(
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/stat/report/%s.user", siteName, interval),
+		&reportQuery{
+			Attrs: []string{"rx_bytes", "tx_bytes", "time"},
+			Start: unixMilli(start),
+			End:   unixMilli(end),
+			MACs:  []string{hw.String()},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req, &v); err != nil {
+		return nil, err
+	}
+
+	if v.Stats == nil {
+		return []*UserStat{}, nil
+	}
+
+	return v.Stats, nil
+}
+
+// StationDPIHistory returns historical per-application network activity
+// statistics for the client with the specified MAC address on a specified
+// site name, at the specified interval between start and end.
+//
+// One DPIHistoryBucket is returned for each application used by the client
+// in each interval, so the result can be large: a 5 minute report spanning a
+// day for a busy client may contain tens of thousands of buckets.  Callers
+// should prefer coarser intervals or shorter windows where possible.
+//
+// Deep packet inspection must be enabled on the site for the UniFi Controller
+// to collect this data.  If it is disabled, or there is no data for the client
+// within the window, an empty slice is returned.
+func (c *Client) StationDPIHistory(siteName string, mac string, interval StatInterval, start, end time.Time) ([]*DPIHistoryBucket, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	var v struct {
+		Records []dpiHistoryRecord `json:"data"`
+	}
+
+	req, err := c.newRequest(
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/stat/report/%s.stadpi", siteName, interval),
+		&reportQuery{
+			Attrs: []string{"rx_bytes", "tx_bytes", "time"},
+			Start: unixMilli(start),
+			End:   unixMilli(end),
+			MACs:  []string{hw.String()},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req, &v); err != nil {
+		return nil, err
+	}
+
+	buckets := make([]*DPIHistoryBucket, 0, len(v.Records))
+	for _, r := range v.Records {
+		t := fromUnixMilli(r.Time)
+		for _, a := range r.ByApp {
+			buckets = append(buckets, &DPIHistoryBucket{
+				Time:          t,
+				Application:   a.App,
+				Category:      a.Cat,
+				ReceiveBytes:  int64(a.RxBytes),
+				TransmitBytes: int64(a.TxBytes),
+			})
+		}
+	}
+
+	return buckets, nil
+}
+
+// A DPIHistoryBucket contains a client's network activity statistics for a
+// single application during a single interval of a historical statistics
+// report.  Application and Category are the identifiers assigned by the
+// UniFi Controller's deep packet inspection engine.
+type DPIHistoryBucket struct {
+	Time          time.Time
+	Application   int
+	Category      int
+	ReceiveBytes  int64
+	TransmitBytes int64
+}
+
+// A dpiHistoryRecord is the raw structure of a single interval of a
+// per-application historical statistics report.
+type dpiHistoryRecord struct {
+	Time  int64 `json:"time"`
+	ByApp []struct {
+		App     int     `json:"app"`
+		Cat     int     `json:"cat"`
+		RxBytes float64 `json:"rx_bytes"`
+		TxBytes float64 `json:"tx_bytes"`
+	} `json:"by_app"`
+}
+
+// A reportQuery is the raw structure of a query for a historical statistics
+// report.
+type reportQuery struct {
+	Attrs []string `json:"attrs"`
+	Start int64    `json:"start"`
+	End   int64    `json:"end"`
+	MACs  []string `json:"macs,omitempty"`
+}
+
+// A UserStat contains a client's network activity statistics for a single
+// interval of a historical statistics report.
+type UserStat struct {
+	Time          time.Time
+	ReceiveBytes  int64
+	TransmitBytes int64
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a UserStat is unmarshaled.
+func (*UserStat) raw() interface{} { return new(userStat) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of a UserStat.
+func (s *UserStat) UnmarshalJSON(b []byte) error {
+	var us userStat
+	if err := json.Unmarshal(b, &us); err != nil {
+		return err
+	}
+
+	*s = UserStat{
+		Time:          fromUnixMilli(us.Time),
+		ReceiveBytes:  int64(us.RxBytes),
+		TransmitBytes: int64(us.TxBytes),
+	}
+
+	return nil
+}
+
+// A userStat is the raw structure of a UserStat returned from the UniFi
+// Controller API.
+type userStat struct {
+	O       string  `json:"o"`
+	OID     string  `json:"oid"`
+	RxBytes float64 `json:"rx_bytes"`
+	Time    int64   `json:"time"`
+	TxBytes float64 `json:"tx_bytes"`
+	User    string  `json:"user"`
+}
+
+// unixMilli returns t as a UNIX timestamp in milliseconds, as used by
+// historical statistics reports.
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// fromUnixMilli returns the time represented by a UNIX timestamp in
+// milliseconds.
+func fromUnixMilli(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -0,0 +1,163 @@
+package unifi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClientUserStats(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantMAC  = "de:ad:be:ef:de:ad"
+	)
+
+	var (
+		start = time.Date(2016, time.January, 01, 0, 0, 0, 0, time.UTC)
+		end   = start.Add(2 * time.Hour)
+	)
+
+	wantQuery := &reportQuery{
+		Attrs: []string{"rx_bytes", "tx_bytes", "time"},
+		Start: 1451606400000,
+		End:   1451613600000,
+		MACs:  []string{wantMAC},
+	}
+
+	var tests = []struct {
+		desc  string
+		stats []userStat
+		want  []*UserStat
+	}{
+		{
+			desc: "no data",
+			want: []*UserStat{},
+		},
+		{
+			desc: "OK",
+			stats: []userStat{
+				{Time: 1451606400000, RxBytes: 80, TxBytes: 20, User: wantMAC},
+				{Time: 1451610000000, RxBytes: 1.5e10, TxBytes: 0, User: wantMAC},
+			},
+			want: []*UserStat{
+				{Time: start, ReceiveBytes: 80, TransmitBytes: 20},
+				{Time: start.Add(time.Hour), ReceiveBytes: 15000000000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := struct {
+				Stats []userStat `json:"data"`
+			}{
+				Stats: tt.stats,
+			}
+
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodPost,
+				fmt.Sprintf("/api/s/%s/stat/report/hourly.user", wantSite),
+				wantQuery,
+				v,
+			))
+			defer done()
+
+			stats, err := c.UserStats(wantSite, "DE:AD:BE:EF:DE:AD", IntervalHourly, start, end)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.UserStats: %v", err)
+			}
+
+			for _, s := range stats {
+				s.Time = s.Time.UTC()
+			}
+
+			if want, got := tt.want, stats; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected UserStats:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientStationDPIHistory(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantMAC  = "de:ad:be:ef:de:ad"
+	)
+
+	var (
+		start = time.Date(2016, time.January, 01, 0, 0, 0, 0, time.UTC)
+		end   = start.Add(2 * time.Hour)
+	)
+
+	wantQuery := &reportQuery{
+		Attrs: []string{"rx_bytes", "tx_bytes", "time"},
+		Start: 1451606400000,
+		End:   1451613600000,
+		MACs:  []string{wantMAC},
+	}
+
+	var tests = []struct {
+		desc    string
+		records []map[string]interface{}
+		want    []*DPIHistoryBucket
+	}{
+		{
+			desc: "DPI disabled",
+			want: []*DPIHistoryBucket{},
+		},
+		{
+			desc: "OK",
+			records: []map[string]interface{}{
+				{
+					"time": 1451606400000,
+					"by_app": []map[string]interface{}{
+						{"app": 94, "cat": 4, "rx_bytes": 1.5e9, "tx_bytes": 1e6},
+						{"app": 5, "cat": 3, "rx_bytes": 80, "tx_bytes": 20},
+					},
+				},
+				{
+					"time": 1451610000000,
+					"by_app": []map[string]interface{}{
+						{"app": 94, "cat": 4, "rx_bytes": 2e9, "tx_bytes": 2e6},
+					},
+				},
+			},
+			want: []*DPIHistoryBucket{
+				{Time: start, Application: 94, Category: 4, ReceiveBytes: 1500000000, TransmitBytes: 1000000},
+				{Time: start, Application: 5, Category: 3, ReceiveBytes: 80, TransmitBytes: 20},
+				{Time: start.Add(time.Hour), Application: 94, Category: 4, ReceiveBytes: 2000000000, TransmitBytes: 2000000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodPost,
+				fmt.Sprintf("/api/s/%s/stat/report/hourly.stadpi", wantSite),
+				wantQuery,
+				map[string]interface{}{"data": tt.records},
+			))
+			defer done()
+
+			buckets, err := c.StationDPIHistory(wantSite, "DE:AD:BE:EF:DE:AD", IntervalHourly, start, end)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.StationDPIHistory: %v", err)
+			}
+
+			for _, b := range buckets {
+				b.Time = b.Time.UTC()
+			}
+
+			if want, got := tt.want, buckets; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected DPIHistoryBuckets:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
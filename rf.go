@@ -0,0 +1,127 @@
+package unifi
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// StartSpectrumScan instructs the access point with the specified MAC address
+// for a specified site name to begin an RF spectrum scan.  Clients connected
+// to the access point are disconnected while the scan runs, which may take
+// several minutes.
+func (c *Client) StartSpectrumScan(siteName string, mac string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return err
+	}
+
+	return c.devmgr(context.Background(), siteName, &deviceCommand{
+		Command: "spectrum-scan",
+		MAC:     hw.String(),
+	})
+}
+
+// OptimizeChannels instructs the UniFi Controller to automatically select the
+// channels used by all access points on a specified site name.
+//
+// Optimization runs asynchronously: OptimizeChannels returns as soon as the
+// UniFi Controller has accepted the command, and channel changes are applied
+// over the following minutes.  ChannelRecommendations can be used afterward
+// to inspect the resulting RF environment.  If the UniFi Controller rejects
+// the command, even with HTTP 200, the error from its meta block is returned.
+// If the UniFi Controller does not support channel optimization, that error
+// is also wrapped with ErrUnsupported.
+func (c *Client) OptimizeChannels(siteName string) error {
+	err := c.devmgr(context.Background(), siteName, &deviceCommand{
+		Command: "optimize",
+	})
+	if isUnsupported(err) {
+		return fmt.Errorf("failed to optimize channels: %w: %w", ErrUnsupported, err)
+	}
+
+	return err
+}
+
+// ChannelRecommendations returns a map of access point MAC addresses to the
+// channels recommended for each, based on the results of the most recent RF
+// spectrum scan for a specified site name.  Channels in different bands
+// cannot be compared, so a channel is recommended for each band scanned by
+// an access point, keyed by band: "2.4GHz" or "5GHz".  The recommended
+// channel for a band is the scanned channel in that band with the lowest
+// utilization.
+//
+// Access points only report scan results after a scan has completed, so
+// StartSpectrumScan may need to be called first.  Access points without scan
+// results are omitted, and an empty map is returned if no recommendations
+// are available.
+func (c *Client) ChannelRecommendations(siteName string) (map[string]map[string]int, error) {
+	scans, err := getList[spectrumScan](context.Background(), c, siteName, "stat/spectrum-scan")
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make(map[string]map[string]int, len(scans))
+	for _, s := range scans {
+		if len(s.SpectrumTable) == 0 {
+			continue
+		}
+
+		hw, err := net.ParseMAC(s.MAC)
+		if err != nil {
+			return nil, err
+		}
+
+		// Prefer the least utilized channel in each band, and the lowest
+		// channel number when utilization is equal.
+		best := make(map[string]spectrumResult)
+		for _, st := range s.SpectrumTable {
+			band := st.band()
+			b, ok := best[band]
+			if !ok || st.Utilization < b.Utilization ||
+				(st.Utilization == b.Utilization && st.Channel < b.Channel) {
+				best[band] = st
+			}
+		}
+
+		bands := make(map[string]int, len(best))
+		for band, st := range best {
+			bands[band] = st.Channel
+		}
+
+		channels[hw.String()] = bands
+	}
+
+	return channels, nil
+}
+
+// A spectrumScan is the raw structure of the RF spectrum scan results
+// returned from the UniFi Controller API.
+type spectrumScan struct {
+	MAC           string           `json:"mac"`
+	SpectrumTable []spectrumResult `json:"spectrum_table"`
+}
+
+// A spectrumResult is the raw structure of the scan result for a single
+// channel in a spectrumScan.
+type spectrumResult struct {
+	Channel      int     `json:"channel"`
+	Interference float64 `json:"interference"`
+	Radio        string  `json:"radio"`
+	Utilization  float64 `json:"utilization"`
+	Width        int     `json:"width"`
+}
+
+// band returns the band of the channel scanned for a spectrumResult, using
+// the radio which scanned it if reported, or otherwise the channel number:
+// 2.4GHz channels are numbered 1 through 14.
+func (r spectrumResult) band() string {
+	switch {
+	case r.Radio == radioNA:
+		return radio5GHz
+	case r.Radio == radioNG, r.Channel <= 14:
+		return radio24GHz
+	default:
+		return radio5GHz
+	}
+}
@@ -0,0 +1,181 @@
+package unifi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientStartSpectrumScan(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/devmgr", wantSite),
+		map[string]string{
+			"cmd": "spectrum-scan",
+			"mac": "de:ad:be:ef:00:01",
+		},
+		nil,
+	))
+	defer done()
+
+	if err := c.StartSpectrumScan(wantSite, "DE:AD:BE:EF:00:01"); err != nil {
+		t.Fatalf("unexpected error from Client.StartSpectrumScan: %v", err)
+	}
+}
+
+func TestClientOptimizeChannels(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/devmgr", wantSite),
+		map[string]string{"cmd": "optimize"},
+		nil,
+	))
+	defer done()
+
+	if err := c.OptimizeChannels(wantSite); err != nil {
+		t.Fatalf("unexpected error from Client.OptimizeChannels: %v", err)
+	}
+}
+
+func TestClientOptimizeChannelsError(t *testing.T) {
+	var tests = []struct {
+		desc   string
+		status int
+		msg    string
+		err    error
+	}{
+		{
+			desc:   "unsupported HTTP 400",
+			status: http.StatusBadRequest,
+			msg:    "api.err.UnknownCommand",
+			err:    ErrUnsupported,
+		},
+		{
+			desc:   "unsupported HTTP 200",
+			status: http.StatusOK,
+			msg:    "api.err.UnknownCommand",
+			err:    ErrUnsupported,
+		},
+		{
+			desc:   "permission denied HTTP 200",
+			status: http.StatusOK,
+			msg:    "api.err.NoPermission",
+			err:    ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", jsonContentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"` + tt.msg + `"},"data":[]}`))
+			})
+			defer done()
+
+			err := c.OptimizeChannels("default")
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", tt.msg, err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestClientChannelRecommendations(t *testing.T) {
+	const wantSite = "default"
+
+	var tests = []struct {
+		desc     string
+		scans    []map[string]interface{}
+		channels map[string]map[string]int
+	}{
+		{
+			desc:     "no scans",
+			channels: map[string]map[string]int{},
+		},
+		{
+			desc: "no results",
+			scans: []map[string]interface{}{{
+				"mac": "de:ad:be:ef:00:01",
+			}},
+			channels: map[string]map[string]int{},
+		},
+		{
+			desc: "OK",
+			scans: []map[string]interface{}{
+				{
+					"mac": "de:ad:be:ef:00:01",
+					"spectrum_table": []map[string]interface{}{
+						{"channel": 1, "utilization": 40},
+						{"channel": 6, "utilization": 10},
+						{"channel": 11, "utilization": 25},
+					},
+				},
+				{
+					"mac": "de:ad:be:ef:00:02",
+					"spectrum_table": []map[string]interface{}{
+						{"channel": 48, "utilization": 5},
+						{"channel": 36, "utilization": 5},
+					},
+				},
+			},
+			channels: map[string]map[string]int{
+				"de:ad:be:ef:00:01": {"2.4GHz": 6},
+				"de:ad:be:ef:00:02": {"5GHz": 36},
+			},
+		},
+		{
+			// The busiest 5GHz channel is less utilized than the quietest
+			// 2.4GHz channel, but each band gets its own recommendation.
+			desc: "dual-band",
+			scans: []map[string]interface{}{{
+				"mac": "de:ad:be:ef:00:01",
+				"spectrum_table": []map[string]interface{}{
+					{"channel": 1, "utilization": 40},
+					{"channel": 11, "utilization": 30},
+					{"channel": 36, "utilization": 20},
+					{"channel": 149, "utilization": 5},
+					{"channel": 157, "radio": "na", "utilization": 10},
+				},
+			}},
+			channels: map[string]map[string]int{
+				"de:ad:be:ef:00:01": {"2.4GHz": 11, "5GHz": 149},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/stat/spectrum-scan", wantSite),
+				nil,
+				map[string]interface{}{"data": tt.scans},
+			))
+			defer done()
+
+			channels, err := c.ChannelRecommendations(wantSite)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.ChannelRecommendations: %v", err)
+			}
+
+			if want, got := tt.channels, channels; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected channel recommendations:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
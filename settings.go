@@ -0,0 +1,315 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SNMPSettings contains the SNMP monitoring settings for a site.
+type SNMPSettings struct {
+	ID        string
+	Community string
+	Enabled   bool
+	SiteID    string
+}
+
+// String returns a string representation of SNMPSettings, with the community
+// string masked so that it is not accidentally logged.
+func (s SNMPSettings) String() string {
+	return fmt.Sprintf("{ID:%s Community:%s Enabled:%t SiteID:%s}",
+		s.ID, mask(s.Community), s.Enabled, s.SiteID)
+}
+
+// GoString returns a Go syntax representation of SNMPSettings, with the
+// community string masked.
+func (s SNMPSettings) GoString() string {
+	return fmt.Sprintf("unifi.SNMPSettings{ID:%q, Community:%q, Enabled:%t, SiteID:%q}",
+		s.ID, mask(s.Community), s.Enabled, s.SiteID)
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// SNMPSettings is unmarshaled.
+func (*SNMPSettings) raw() interface{} { return new(snmpSettings) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of SNMPSettings.
+func (s *SNMPSettings) UnmarshalJSON(b []byte) error {
+	var ss snmpSettings
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+
+	*s = SNMPSettings{
+		ID:        ss.ID,
+		Community: ss.Community,
+		Enabled:   ss.Enabled,
+		SiteID:    ss.SiteID,
+	}
+
+	return nil
+}
+
+// An snmpSettings is the raw structure of SNMPSettings returned from the
+// UniFi Controller API.
+type snmpSettings struct {
+	ID        string `json:"_id"`
+	Community string `json:"community"`
+	Enabled   bool   `json:"enabled"`
+	Key       string `json:"key"`
+	SiteID    string `json:"site_id"`
+}
+
+// mask masks a secret value for display.
+func mask(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "********"
+}
+
+// SNMPSettings returns the SNMP monitoring settings for a specified site name.
+func (c *Client) SNMPSettings(siteName string) (*SNMPSettings, error) {
+	var s SNMPSettings
+	if err := c.setting(siteName, "snmp", &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// SetSNMPCommunity sets the SNMP community string for a specified site name.
+func (c *Client) SetSNMPCommunity(siteName string, community string) error {
+	return c.updateSetting(siteName, "snmp", func(s map[string]interface{}) error {
+		s["community"] = community
+		return nil
+	})
+}
+
+// GuestPortalSettings contains the guest portal settings for a site.
+type GuestPortalSettings struct {
+	ID               string
+	Auth             string
+	Password         string
+	PortalCustomized bool
+	PortalEnabled    bool
+	RedirectEnabled  bool
+	RedirectHTTPS    bool
+	RedirectURL      string
+	SiteID           string
+}
+
+// String returns a string representation of GuestPortalSettings, with the
+// portal password masked so that it is not accidentally logged.
+func (s GuestPortalSettings) String() string {
+	return fmt.Sprintf("{ID:%s Auth:%s Password:%s PortalCustomized:%t PortalEnabled:%t RedirectEnabled:%t RedirectHTTPS:%t RedirectURL:%s SiteID:%s}",
+		s.ID, s.Auth, mask(s.Password), s.PortalCustomized, s.PortalEnabled,
+		s.RedirectEnabled, s.RedirectHTTPS, s.RedirectURL, s.SiteID)
+}
+
+// GoString returns a Go syntax representation of GuestPortalSettings, with
+// the portal password masked.
+func (s GuestPortalSettings) GoString() string {
+	return fmt.Sprintf("unifi.GuestPortalSettings{ID:%q, Auth:%q, Password:%q, PortalCustomized:%t, PortalEnabled:%t, RedirectEnabled:%t, RedirectHTTPS:%t, RedirectURL:%q, SiteID:%q}",
+		s.ID, s.Auth, mask(s.Password), s.PortalCustomized, s.PortalEnabled,
+		s.RedirectEnabled, s.RedirectHTTPS, s.RedirectURL, s.SiteID)
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// GuestPortalSettings is unmarshaled.
+func (*GuestPortalSettings) raw() interface{} { return new(guestPortalSettings) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of
+// GuestPortalSettings.
+func (s *GuestPortalSettings) UnmarshalJSON(b []byte) error {
+	var gs guestPortalSettings
+	if err := json.Unmarshal(b, &gs); err != nil {
+		return err
+	}
+
+	*s = GuestPortalSettings{
+		ID:               gs.ID,
+		Auth:             gs.Auth,
+		Password:         gs.XPassword,
+		PortalCustomized: gs.PortalCustomized,
+		PortalEnabled:    gs.PortalEnabled,
+		RedirectEnabled:  gs.RedirectEnabled,
+		RedirectHTTPS:    gs.RedirectHTTPS,
+		RedirectURL:      gs.RedirectURL,
+		SiteID:           gs.SiteID,
+	}
+
+	return nil
+}
+
+// A guestPortalSettings is the raw structure of GuestPortalSettings returned
+// from the UniFi Controller API.
+type guestPortalSettings struct {
+	ID               string `json:"_id"`
+	Auth             string `json:"auth"`
+	Key              string `json:"key"`
+	PortalCustomized bool   `json:"portal_customized"`
+	PortalEnabled    bool   `json:"portal_enabled"`
+	RedirectEnabled  bool   `json:"redirect_enabled"`
+	RedirectHTTPS    bool   `json:"redirect_https"`
+	RedirectURL      string `json:"redirect_url"`
+	SiteID           string `json:"site_id"`
+	XPassword        string `json:"x_password"`
+}
+
+// GuestPortalSettings returns the guest portal settings for a specified
+// site name.
+func (c *Client) GuestPortalSettings(siteName string) (*GuestPortalSettings, error) {
+	var s GuestPortalSettings
+	if err := c.setting(siteName, "guest_access", &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// SetGuestPortalRedirect sets the URL guests are redirected to after
+// authenticating with the guest portal for a specified site name.  The URL
+// must be absolute.  An empty URL disables the redirect.
+func (c *Client) SetGuestPortalRedirect(siteName string, redirectURL string) error {
+	if redirectURL != "" {
+		u, err := url.Parse(redirectURL)
+		if err != nil {
+			return err
+		}
+		if !u.IsAbs() || u.Host == "" {
+			return fmt.Errorf("guest portal redirect URL must be absolute: %q", redirectURL)
+		}
+	}
+
+	return c.updateSetting(siteName, "guest_access", func(s map[string]interface{}) error {
+		s["redirect_enabled"] = redirectURL != ""
+		s["redirect_url"] = redirectURL
+		return nil
+	})
+}
+
+// AlertSettings contains the settings which control whether events on a site
+// raise alerts.  Each field reports whether alerts are enabled for the
+// corresponding event.
+type AlertSettings struct {
+	ID     string
+	SiteID string
+
+	APLostContact      bool
+	SwitchLostContact  bool
+	GatewayLostContact bool
+	RogueAP            bool
+	IPSAlert           bool
+
+	// Other contains the raw values of any alert keys not known to this
+	// package, so that they are not lost.
+	Other map[string]json.RawMessage
+}
+
+// AlertSettings returns the alert settings for a specified site name.
+func (c *Client) AlertSettings(siteName string) (*AlertSettings, error) {
+	var s AlertSettings
+	if err := c.setting(siteName, "alerts", &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// UnmarshalJSON unmarshals the raw JSON representation of AlertSettings.
+func (s *AlertSettings) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var as AlertSettings
+	fields := map[string]interface{}{
+		"_id":     &as.ID,
+		"site_id": &as.SiteID,
+		"key":     new(string),
+
+		"EVT_AP_Lost_Contact":  &as.APLostContact,
+		"EVT_SW_Lost_Contact":  &as.SwitchLostContact,
+		"EVT_GW_Lost_Contact":  &as.GatewayLostContact,
+		"EVT_AP_DetectRogueAP": &as.RogueAP,
+		"EVT_IPS_IpsAlert":     &as.IPSAlert,
+	}
+
+	for k, v := range raw {
+		f, ok := fields[k]
+		if !ok {
+			if as.Other == nil {
+				as.Other = make(map[string]json.RawMessage)
+			}
+			as.Other[k] = v
+			continue
+		}
+
+		if err := json.Unmarshal(v, f); err != nil {
+			return fmt.Errorf("failed to parse alert setting %q: %v", k, err)
+		}
+	}
+
+	*s = as
+	return nil
+}
+
+// setting retrieves the settings group with the specified key for a site,
+// and unmarshals it into v.  If the group does not exist, ErrNotFound is
+// returned.
+func (c *Client) setting(siteName string, key string, v interface{}) error {
+	settings, err := getList[json.RawMessage](context.Background(), c, siteName, "get/setting/"+key)
+	if err != nil {
+		return err
+	}
+
+	// The UniFi Controller may return more settings groups than the one
+	// requested, so find it by key.
+	for _, b := range settings {
+		var k struct {
+			Key string `json:"key"`
+		}
+		if err := json.Unmarshal(*b, &k); err != nil {
+			return err
+		}
+
+		if k.Key == key {
+			return unmarshal(*b, v, c.StrictJSON)
+		}
+	}
+
+	return ErrNotFound
+}
+
+// updateSetting performs a read-modify-write of the settings group with the
+// specified key for a site.  fn is called to modify the raw settings, which
+// are then written back in their entirety so that fields not known to this
+// package are preserved.
+func (c *Client) updateSetting(siteName string, key string, fn func(s map[string]interface{}) error) error {
+	var s map[string]interface{}
+	if err := c.setting(siteName, key, &s); err != nil {
+		return err
+	}
+
+	if err := fn(s); err != nil {
+		return err
+	}
+
+	id, _ := s["_id"].(string)
+
+	req, err := c.newRequest(
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/set/setting/%s/%s", siteName, key, id),
+		s,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
@@ -0,0 +1,324 @@
+package unifi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientSNMPSettings(t *testing.T) {
+	const wantSite = "default"
+
+	wantSettings := &SNMPSettings{
+		ID:        "abcdef123457890",
+		Community: "secret",
+		Enabled:   true,
+	}
+
+	v := map[string]interface{}{
+		"data": []map[string]interface{}{
+			{"key": "mgmt", "_id": "0123456789abcdef"},
+			{"key": "snmp", "_id": wantSettings.ID, "community": wantSettings.Community, "enabled": true},
+		},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/get/setting/snmp", wantSite),
+		nil,
+		v,
+	))
+	defer done()
+
+	s, err := c.SNMPSettings(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.SNMPSettings: %v", err)
+	}
+
+	if want, got := wantSettings, s; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected SNMPSettings:\n- want: %#v\n-  got: %#v",
+			want, got)
+	}
+
+	for _, str := range []string{
+		s.String(),
+		fmt.Sprint(*s),
+		fmt.Sprintf("%v", struct{ S SNMPSettings }{S: *s}),
+		fmt.Sprintf("%#v", s),
+		fmt.Sprintf("%#v", *s),
+	} {
+		if strings.Contains(str, wantSettings.Community) {
+			t.Fatalf("SNMPSettings string contains community: %s", str)
+		}
+	}
+}
+
+func TestClientSNMPSettingsStrict(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    string
+		err  error
+	}{
+		{
+			desc: "OK",
+			b:    `{"data":[{"key":"snmp","_id":"abcdef","community":"secret","enabled":true,"site_id":"default"}]}`,
+		},
+		{
+			desc: "unknown field",
+			b:    `{"data":[{"key":"snmp","_id":"abcdef","community":"secret","foo":1}]}`,
+			err:  errors.New(`unknown field "foo"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", jsonContentType)
+				_, _ = w.Write([]byte(tt.b))
+			})
+			defer done()
+			c.StrictJSON = true
+
+			_, err := c.SNMPSettings("default")
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientSetSNMPCommunity(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef123457890"
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/get/setting/snmp", wantSite), nil,
+				map[string]interface{}{
+					"data": []map[string]interface{}{{
+						"_id":       wantID,
+						"key":       "snmp",
+						"community": "public",
+						"enabled":   true,
+					}},
+				},
+			)(w, r)
+		case http.MethodPost:
+			// All other fields must be preserved.
+			testHandler(t, http.MethodPost, fmt.Sprintf("/api/s/%s/set/setting/snmp/%s", wantSite, wantID),
+				map[string]interface{}{
+					"_id":       wantID,
+					"key":       "snmp",
+					"community": "private",
+					"enabled":   true,
+				},
+				nil,
+			)(w, r)
+		}
+	})
+	defer done()
+
+	if err := c.SetSNMPCommunity(wantSite, "private"); err != nil {
+		t.Fatalf("unexpected error from Client.SetSNMPCommunity: %v", err)
+	}
+}
+
+func TestClientSettingNotFound(t *testing.T) {
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		"/api/s/default/get/setting/snmp",
+		nil,
+		map[string]interface{}{"data": []interface{}{}},
+	))
+	defer done()
+
+	if _, err := c.SNMPSettings("default"); err != ErrNotFound {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrNotFound, err)
+	}
+}
+
+func TestClientGuestPortalSettings(t *testing.T) {
+	const wantSite = "default"
+
+	wantSettings := &GuestPortalSettings{
+		ID:               "abcdef123457890",
+		Auth:             "password",
+		Password:         "secret",
+		PortalCustomized: true,
+		PortalEnabled:    true,
+		RedirectEnabled:  true,
+		RedirectURL:      "https://example.com/",
+	}
+
+	v := map[string]interface{}{
+		"data": []map[string]interface{}{{
+			"key":               "guest_access",
+			"_id":               wantSettings.ID,
+			"auth":              wantSettings.Auth,
+			"x_password":        wantSettings.Password,
+			"portal_customized": true,
+			"portal_enabled":    true,
+			"redirect_enabled":  true,
+			"redirect_url":      wantSettings.RedirectURL,
+		}},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/get/setting/guest_access", wantSite),
+		nil,
+		v,
+	))
+	defer done()
+
+	s, err := c.GuestPortalSettings(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.GuestPortalSettings: %v", err)
+	}
+
+	if want, got := wantSettings, s; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected GuestPortalSettings:\n- want: %#v\n-  got: %#v",
+			want, got)
+	}
+
+	for _, str := range []string{
+		s.String(),
+		fmt.Sprint(*s),
+		fmt.Sprintf("%v", struct{ S GuestPortalSettings }{S: *s}),
+		fmt.Sprintf("%#v", s),
+		fmt.Sprintf("%#v", *s),
+	} {
+		if strings.Contains(str, wantSettings.Password) {
+			t.Fatalf("GuestPortalSettings string contains password: %s", str)
+		}
+	}
+}
+
+func TestClientSetGuestPortalRedirect(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef123457890"
+	)
+
+	var tests = []struct {
+		desc string
+		url  string
+		err  error
+	}{
+		{
+			desc: "invalid URL",
+			url:  "http://[::1",
+			err:  errors.New("missing ']' in host"),
+		},
+		{
+			desc: "relative URL",
+			url:  "/welcome",
+			err:  errors.New("must be absolute"),
+		},
+		{
+			desc: "OK",
+			url:  "https://example.com/welcome",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/get/setting/guest_access", wantSite), nil,
+						map[string]interface{}{
+							"data": []map[string]interface{}{{
+								"_id":              wantID,
+								"key":              "guest_access",
+								"portal_enabled":   true,
+								"redirect_enabled": false,
+							}},
+						},
+					)(w, r)
+				case http.MethodPost:
+					testHandler(t, http.MethodPost, fmt.Sprintf("/api/s/%s/set/setting/guest_access/%s", wantSite, wantID),
+						map[string]interface{}{
+							"_id":              wantID,
+							"key":              "guest_access",
+							"portal_enabled":   true,
+							"redirect_enabled": true,
+							"redirect_url":     tt.url,
+						},
+						nil,
+					)(w, r)
+				}
+			})
+			defer done()
+
+			err := c.SetGuestPortalRedirect(wantSite, tt.url)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error from Client.SetGuestPortalRedirect: %v", err)
+			}
+		})
+	}
+}
+
+func TestClientAlertSettings(t *testing.T) {
+	const wantSite = "default"
+
+	wantSettings := &AlertSettings{
+		ID:                "abcdef123457890",
+		SiteID:            "default",
+		APLostContact:     true,
+		SwitchLostContact: true,
+		RogueAP:           false,
+		IPSAlert:          true,
+		Other: map[string]json.RawMessage{
+			"EVT_XG_Lost_Contact": json.RawMessage(`true`),
+		},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/get/setting/alerts", wantSite),
+		nil,
+		map[string]interface{}{
+			"data": []map[string]interface{}{{
+				"_id":                  wantSettings.ID,
+				"key":                  "alerts",
+				"site_id":              "default",
+				"EVT_AP_Lost_Contact":  true,
+				"EVT_SW_Lost_Contact":  true,
+				"EVT_GW_Lost_Contact":  false,
+				"EVT_AP_DetectRogueAP": false,
+				"EVT_IPS_IpsAlert":     true,
+				"EVT_XG_Lost_Contact":  true,
+			}},
+		},
+	))
+	defer done()
+
+	s, err := c.AlertSettings(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.AlertSettings: %v", err)
+	}
+
+	if want, got := wantSettings, s; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected AlertSettings:\n- want: %#v\n-  got: %#v",
+			want, got)
+	}
+}
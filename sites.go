@@ -1,14 +1,23 @@
 package unifi
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
 // A Site is a physical location with UniFi devices managed by a UniFi
 // Controller.
 type Site struct {
-	ID          string `json:"_id"`
-	Description string `json:"desc"`
-	Name        string `json:"name"`
-	NumAPs      int    `json:"num_ap"`
-	NumStations int    `json:"num_sta"`
-	Role        string `json:"role"`
+	ID           string `json:"_id"`
+	Description  string `json:"desc"`
+	Name         string `json:"name"`
+	NumAPs       int    `json:"num_ap"`
+	NumNewAlarms int    `json:"num_new_alarms"`
+	NumStations  int    `json:"num_sta"`
+	Role         string `json:"role"`
 }
 
 // Sites returns all of the Sites managed by a UniFi Controller.
@@ -29,3 +38,102 @@ func (c *Client) Sites() ([]*Site, error) {
 	_, err = c.do(req, &v)
 	return v.Sites, err
 }
+
+// SitesWithAlarms returns the Sites managed by a UniFi Controller which have
+// unresolved alarms, sorted by their number of new alarms in descending
+// order.  Sites with an equal number of alarms are sorted by name.
+func (c *Client) SitesWithAlarms() ([]*Site, error) {
+	sites, err := c.Sites()
+	if err != nil {
+		return nil, err
+	}
+
+	alarmed := make([]*Site, 0, len(sites))
+	for _, s := range sites {
+		if s.NumNewAlarms > 0 {
+			alarmed = append(alarmed, s)
+		}
+	}
+
+	sort.Slice(alarmed, func(i, j int) bool {
+		if alarmed[i].NumNewAlarms != alarmed[j].NumNewAlarms {
+			return alarmed[i].NumNewAlarms > alarmed[j].NumNewAlarms
+		}
+
+		return alarmed[i].Name < alarmed[j].Name
+	})
+
+	return alarmed, nil
+}
+
+// A SiteExport is a point-in-time export of the configuration and state of a
+// Site.  Each section contains the JSON data returned by the UniFi Controller
+// verbatim, so that an export captures fields which are not modeled by this
+// package.
+//
+// If a section could not be retrieved, it is null and the error encountered
+// is recorded in Errors, keyed by the section's JSON name.
+type SiteExport struct {
+	Site          string            `json:"site"`
+	Time          time.Time         `json:"time"`
+	Devices       json.RawMessage   `json:"devices"`
+	Stations      json.RawMessage   `json:"stations"`
+	WLANs         json.RawMessage   `json:"wlans"`
+	Networks      json.RawMessage   `json:"networks"`
+	FirewallRules json.RawMessage   `json:"firewall_rules"`
+	Errors        map[string]string `json:"errors,omitempty"`
+}
+
+// ExportSite exports the devices, stations, WLANs, networks, and firewall
+// rules for a specified site name as a SiteExport.  The sections are
+// retrieved concurrently.
+//
+// A failure to retrieve an individual section is recorded in the SiteExport's
+// Errors field rather than causing ExportSite to fail.
+func (c *Client) ExportSite(siteName string) (*SiteExport, error) {
+	e := &SiteExport{
+		Site: siteName,
+		Time: time.Now(),
+	}
+
+	sections := []struct {
+		name     string
+		endpoint string
+		data     *json.RawMessage
+	}{
+		{name: "devices", endpoint: "stat/device", data: &e.Devices},
+		{name: "stations", endpoint: "stat/sta", data: &e.Stations},
+		{name: "wlans", endpoint: "rest/wlanconf", data: &e.WLANs},
+		{name: "networks", endpoint: "rest/networkconf", data: &e.Networks},
+		{name: "firewall_rules", endpoint: "rest/firewallrule", data: &e.FirewallRules},
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	wg.Add(len(sections))
+	for _, s := range sections {
+		go func(name, endpoint string, data *json.RawMessage) {
+			defer wg.Done()
+
+			b, err := c.raw(fmt.Sprintf("/api/s/%s/%s", siteName, endpoint))
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+
+				if e.Errors == nil {
+					e.Errors = make(map[string]string)
+				}
+				e.Errors[name] = err.Error()
+				return
+			}
+
+			*data = b
+		}(s.name, s.endpoint, s.data)
+	}
+	wg.Wait()
+
+	return e, nil
+}
@@ -1,8 +1,11 @@
 package unifi
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -36,3 +39,89 @@ func TestClientSites(t *testing.T) {
 			want, got)
 	}
 }
+
+func TestClientSitesWithAlarms(t *testing.T) {
+	v := struct {
+		Sites []*Site `json:"data"`
+	}{
+		Sites: []*Site{
+			{Name: "quiet"},
+			{Name: "b", NumNewAlarms: 2},
+			{Name: "loud", NumNewAlarms: 10},
+			{Name: "a", NumNewAlarms: 2},
+		},
+	}
+
+	c, done := testClient(t, testHandler(t, http.MethodGet, "/api/self/sites", nil, v))
+	defer done()
+
+	sites, err := c.SitesWithAlarms()
+	if err != nil {
+		t.Fatalf("unexpected error from Client.SitesWithAlarms: %v", err)
+	}
+
+	names := make([]string, 0, len(sites))
+	for _, s := range sites {
+		names = append(names, s.Name)
+	}
+
+	if want, got := []string{"loud", "a", "b"}, names; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Sites:\n- want: %v\n-  got: %v",
+			want, got)
+	}
+}
+
+func TestClientExportSite(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/s/default/rest/firewallrule":
+			w.Header().Set("Content-Type", jsonContentType)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			path := strings.TrimPrefix(r.URL.Path, "/api/s/default/")
+			testHandler(t, http.MethodGet, r.URL.Path, nil, map[string]interface{}{
+				"data": []map[string]string{{"path": path}},
+			})(w, r)
+		}
+	})
+	defer done()
+
+	e, err := c.ExportSite(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.ExportSite: %v", err)
+	}
+
+	if want, got := wantSite, e.Site; want != got {
+		t.Fatalf("unexpected site:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	sections := map[string]json.RawMessage{
+		"stat/device":      e.Devices,
+		"stat/sta":         e.Stations,
+		"rest/wlanconf":    e.WLANs,
+		"rest/networkconf": e.Networks,
+	}
+
+	for path, b := range sections {
+		if want, got := fmt.Sprintf(`[{"path":%q}]`, path), string(b); want != got {
+			t.Fatalf("unexpected section data:\n- want: %v\n-  got: %v",
+				want, got)
+		}
+	}
+
+	if e.FirewallRules != nil {
+		t.Fatalf("expected no firewall rules, but got: %s", string(e.FirewallRules))
+	}
+
+	if want, got := map[string]string{
+		"firewall_rules": "unexpected HTTP status code: 500",
+	}, e.Errors; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected errors:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	if _, err := json.Marshal(e); err != nil {
+		t.Fatalf("failed to marshal SiteExport: %v", err)
+	}
+}
@@ -1,29 +1,317 @@
 package unifi
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"time"
 )
 
 // Stations returns all of the Stations for a specified site name.
 func (c *Client) Stations(siteName string) ([]*Station, error) {
-	var v struct {
-		Stations []*Station `json:"data"`
+	return c.stations(context.Background(), siteName)
+}
+
+// StationByMAC returns the Station with the specified MAC address for a
+// specified site name, without retrieving every Station on the site.  If the
+// client is not currently connected, ErrNotFound is returned.
+//
+// Stations are looked up in the UniFi Controller's list of connected clients,
+// rather than its stored client records (see KnownClient), which also hold
+// clients that have since disconnected.
+func (c *Client) StationByMAC(siteName string, mac net.HardwareAddr) (*Station, error) {
+	if len(mac) == 0 {
+		return nil, errors.New("station MAC address must not be empty")
 	}
 
-	req, err := c.newRequest(
-		"GET",
-		fmt.Sprintf("/api/s/%s/stat/sta", siteName),
-		nil,
-	)
+	return getOne[Station](context.Background(), c, siteName, "stat/sta/"+mac.String())
+}
+
+// stations retrieves all of the Stations for a specified site name.
+func (c *Client) stations(ctx context.Context, siteName string) ([]*Station, error) {
+	return getList[Station](ctx, c, siteName, "stat/sta")
+}
+
+// WaitForStation polls the Stations for a specified site name until the
+// Station with the specified MAC address is observed to be connected, or
+// disconnected if connected is false.
+//
+// Stations are polled using PollUntil: immediately, then after 5 seconds,
+// with the wait doubling after each poll up to a maximum of 40 seconds (eight
+// times the initial interval).  Each wait is extended by a random jitter of up
+// to one fifth, so that many concurrent waiters do not poll the UniFi
+// Controller in lockstep.  WaitForStation does not time out on its own; if ctx
+// is canceled or its deadline is exceeded before the desired state is
+// observed, its error is returned.
+func (c *Client) WaitForStation(ctx context.Context, siteName string, mac string, connected bool) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return err
+	}
+
+	err = PollUntil(ctx, c.pollInterval, 0, func() (bool, error) {
+		stations, err := c.stations(ctx, siteName)
+		if err != nil {
+			return false, err
+		}
+
+		var found bool
+		for _, s := range stations {
+			if bytes.Equal(s.MAC, hw) {
+				found = true
+				break
+			}
+		}
+
+		return found == connected, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		// A request interrupted by ctx reports its own error.
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// StationAP returns the Device which acts as the access point for the Station
+// with the specified MAC address on a specified site name.
+//
+// If the Station is not connected, ErrNotFound is returned.  If the Station's
+// access point cannot be found, an error wrapping ErrNotFound is returned.
+func (c *Client) StationAP(siteName string, stationMAC string) (*Device, error) {
+	sta, err := c.station(siteName, stationMAC)
 	if err != nil {
 		return nil, err
 	}
+	if sta.APMAC == nil {
+		return nil, fmt.Errorf("station %s is not associated with an access point: %w",
+			sta.MAC, ErrNotFound)
+	}
 
-	_, err = c.do(req, &v)
-	return v.Stations, err
+	devices, err := c.Devices(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range devices {
+		if bytes.Equal(d.MAC, sta.APMAC) {
+			return d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("access point %s for station %s: %w",
+		sta.APMAC, sta.MAC, ErrNotFound)
+}
+
+// StationWLAN returns the WLAN which the Station with the specified MAC
+// address on a specified site name is connected to, matched by ESSID.
+//
+// If the Station is not connected, ErrNotFound is returned.  If the Station
+// does not report an ESSID, such as a wired Station or one connected to a
+// hidden network, or no WLAN matches its ESSID, an error wrapping ErrNotFound
+// is returned.
+func (c *Client) StationWLAN(siteName string, stationMAC string) (*WLAN, error) {
+	sta, err := c.station(siteName, stationMAC)
+	if err != nil {
+		return nil, err
+	}
+	if sta.ESSID == "" {
+		return nil, fmt.Errorf("station %s does not report an ESSID: %w",
+			sta.MAC, ErrNotFound)
+	}
+
+	wlans, err := c.WLANs(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	// ESSIDs are case-sensitive.
+	for _, w := range wlans {
+		if w.Name == sta.ESSID {
+			return w, nil
+		}
+	}
+
+	return nil, fmt.Errorf("WLAN %q for station %s: %w",
+		sta.ESSID, sta.MAC, ErrNotFound)
+}
+
+// station returns the connected Station with the specified MAC address on a
+// site, or ErrNotFound if it is not connected.
+func (c *Client) station(siteName string, stationMAC string) (*Station, error) {
+	mac, err := net.ParseMAC(stationMAC)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.StationByMAC(siteName, mac)
+}
+
+// TopTalkers returns the n Stations for a specified site name which have
+// transferred the most bytes, in descending order of total bytes received and
+// transmitted.  Stations with equal totals are ordered by MAC address, so that
+// results are stable across calls.
+//
+// If n exceeds the number of Stations, all Stations are returned.
+func (c *Client) TopTalkers(siteName string, n int) ([]*Station, error) {
+	stations, err := c.Stations(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	total := func(s *Station) int64 {
+		if s.Stats == nil {
+			return 0
+		}
+
+		return s.Stats.ReceiveBytes + s.Stats.TransmitBytes
+	}
+
+	sort.Slice(stations, func(i, j int) bool {
+		ti, tj := total(stations[i]), total(stations[j])
+		if ti != tj {
+			return ti > tj
+		}
+
+		return bytes.Compare(stations[i].MAC, stations[j].MAC) < 0
+	})
+
+	if n < 0 {
+		n = 0
+	}
+	if n < len(stations) {
+		stations = stations[:n]
+	}
+
+	return stations, nil
+}
+
+// IPConflicts returns the Stations for a specified site name which report the
+// same IP address as at least one other Station with a different MAC address,
+// keyed by that IP address in its canonical form.  Stations without an IP
+// address are ignored.
+func (c *Client) IPConflicts(siteName string) (map[string][]*Station, error) {
+	stations, err := c.Stations(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	byIP := make(map[string][]*Station)
+	for _, s := range stations {
+		if s.IP == nil || s.IP.IsUnspecified() {
+			continue
+		}
+
+		ip := s.IP.String()
+		byIP[ip] = append(byIP[ip], s)
+	}
+
+	conflicts := make(map[string][]*Station)
+	for ip, ss := range byIP {
+		for _, s := range ss[1:] {
+			if !bytes.Equal(s.MAC, ss[0].MAC) {
+				conflicts[ip] = ss
+				break
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// APLoadDistribution returns a map of access point MAC addresses to the
+// fraction of wireless Stations on a specified site name which are connected
+// to each access point, in the range 0 to 1.  Access points with no connected
+// Stations are reported with a fraction of 0.
+//
+// Wired Stations are not counted.  If no wireless Stations are connected to
+// the site, an empty map is returned.
+func (c *Client) APLoadDistribution(siteName string) (map[string]float64, error) {
+	devices, err := c.Devices(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	stations, err := c.Stations(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, d := range devices {
+		if d.Type == DeviceTypeAccessPoint && d.MAC != nil {
+			counts[d.MAC.String()] = 0
+		}
+	}
+
+	var total int
+	for _, s := range stations {
+		if s.IsWired || s.APMAC == nil {
+			continue
+		}
+
+		counts[s.APMAC.String()]++
+		total++
+	}
+
+	load := make(map[string]float64, len(counts))
+	if total == 0 {
+		return load, nil
+	}
+
+	for mac, n := range counts {
+		load[mac] = float64(n) / float64(total)
+	}
+
+	return load, nil
+}
+
+// SignalDistribution returns the number of wireless Stations on a specified
+// site name in each of the signal strength buckets "excellent", "good",
+// "fair", and "poor", determined by each Station's RSSI:
+//
+//   - excellent: RSSI of 45 or greater, roughly -50 dBm or stronger
+//   - good: RSSI from 35 to 44
+//   - fair: RSSI from 25 to 34
+//   - poor: RSSI below 25, roughly -70 dBm or weaker
+//
+// Wired Stations are not counted.  Every bucket is present in the returned
+// map, even if no Stations fall within it.
+func (c *Client) SignalDistribution(siteName string) (map[string]int, error) {
+	stations, err := c.Stations(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	dist := map[string]int{
+		"excellent": 0,
+		"good":      0,
+		"fair":      0,
+		"poor":      0,
+	}
+
+	for _, s := range stations {
+		if s.IsWired {
+			continue
+		}
+
+		switch {
+		case s.RSSI >= 45:
+			dist["excellent"]++
+		case s.RSSI >= 35:
+			dist["good"]++
+		case s.RSSI >= 25:
+			dist["fair"]++
+		default:
+			dist["poor"]++
+		}
+	}
+
+	return dist, nil
 }
 
 // A Station is a client connected to a UniFi access point.
@@ -31,22 +319,44 @@ type Station struct {
 	ID              string
 	APMAC           net.HardwareAddr
 	AssociationTime time.Time
+	Authorized      bool
+	BSSID           string
+	CCQ             int // Client connection quality, in tenths of a percent
 	Channel         int
+	ESSID           string
 	FirstSeen       time.Time
+	FixedIP         net.IP // Only set if UseFixedIP is true
 	Hostname        string // Device-provided name
 	IdleTime        time.Duration
 	IP              net.IP
+	IsGuest         bool
 	IsWired         bool
 	LastSeen        time.Time
 	MAC             net.HardwareAddr
 	RoamCount       int
 	Name            string // Unifi-set name
+	NetworkID       string
 	Noise           int
+	RadioProto      string // Wireless protocol, such as "ac" or "ng"
 	RSSI            int
+	Signal          int // Signal strength in dBm
 	SiteID          string
 	Stats           *StationStats
 	Uptime          time.Duration
+	UseFixedIP      bool
 	UserID          string
+
+	// SwitchMAC and SwitchPort identify the switch and port a wired Station
+	// is plugged into.  They are unset for wireless Stations.
+	SwitchMAC  net.HardwareAddr
+	SwitchPort int
+
+	// Anomalies contains descriptions of the problems the UniFi Controller
+	// has detected with the Station's connection.  Anomalies reported as a
+	// bitfield are described as "unknown(<bit>)" for each set bit, because
+	// the meaning of those bits is not documented.  It is nil if the UniFi
+	// Controller does not report anomalies, and empty if none were detected.
+	Anomalies []string
 }
 
 // StationStats contains station network activity statistics.
@@ -58,8 +368,35 @@ type StationStats struct {
 	TransmitPackets int64
 	TransmitPower   int
 	TransmitRate    int
+
+	// Wireless transmission counters, which can be used to compute a
+	// Station's retry ratio.  Wired Stations report zero.
+	TransmitAttempts int64
+	TransmitRetries  int64
+	TransmitFailed   int64
+
+	// Current throughput in bytes per second, as most recently measured by
+	// the UniFi Controller.
+	ReceiveRateBytes  int64
+	TransmitRateBytes int64
+	TotalRateBytes    int64
+}
+
+// RandomizedMAC reports whether the Station's MAC address is locally
+// administered rather than assigned by its manufacturer, as is the case for
+// clients which randomize their MAC address for privacy.
+func (s *Station) RandomizedMAC() bool { return locallyAdministered(s.MAC) }
+
+// locallyAdministered reports whether mac has its locally administered bit
+// set.
+func locallyAdministered(mac net.HardwareAddr) bool {
+	return len(mac) > 0 && mac[0]&0x02 != 0
 }
 
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a Station is unmarshaled.
+func (*Station) raw() interface{} { return new(station) }
+
 // UnmarshalJSON unmarshals the raw JSON representation of a Station.
 func (s *Station) UnmarshalJSON(b []byte) error {
 	var sta station
@@ -77,21 +414,69 @@ func (s *Station) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	// Stations which have not associated with an access point, such as
+	// wired clients, do not report an association time.
+	var assoc time.Time
+	if sta.AssocTime != 0 {
+		assoc = time.Unix(int64(sta.AssocTime), 0)
+	}
+
+	// Wired clients may only report uptime as observed by the switch or
+	// gateway they are connected to.
+	uptime := sta.Uptime
+	if sta.IsWired && uptime == 0 {
+		uptime = sta.UptimeByUsw
+		if uptime == 0 {
+			uptime = sta.UptimeByUgw
+		}
+	}
+
+	var (
+		swMAC  net.HardwareAddr
+		swPort int
+	)
+	if sta.IsWired && sta.SwMac != "" {
+		swMAC, err = net.ParseMAC(sta.SwMac)
+		if err != nil {
+			return err
+		}
+		swPort = sta.SwPort
+	}
+
+	anomalies, err := parseAnomalies(sta.Anomalies)
+	if err != nil {
+		return err
+	}
+
+	var fixedIP net.IP
+	if sta.UseFixedIP {
+		fixedIP = net.ParseIP(sta.FixedIP)
+	}
+
 	*s = Station{
 		ID:              sta.ID,
 		APMAC:           apMAC,
-		AssociationTime: time.Unix(int64(sta.AssocTime), 0),
+		AssociationTime: assoc,
+		Authorized:      sta.Authorized,
+		BSSID:           sta.Bssid,
+		CCQ:             sta.Ccq,
 		Channel:         sta.Channel,
+		ESSID:           sta.Essid,
 		FirstSeen:       time.Unix(int64(sta.FirstSeen), 0),
+		FixedIP:         fixedIP,
 		Hostname:        sta.Hostname,
 		IdleTime:        time.Duration(time.Duration(sta.Idletime) * time.Second),
 		IP:              net.ParseIP(sta.IP),
+		IsGuest:         sta.IsGuest,
 		IsWired:         sta.IsWired,
 		LastSeen:        time.Unix(int64(sta.LastSeen), 0),
 		MAC:             mac,
 		Name:            sta.Name,
+		NetworkID:       sta.NetworkID,
 		Noise:           sta.Noise,
+		RadioProto:      sta.RadioProto,
 		RSSI:            sta.RSSI,
+		Signal:          sta.Signal,
 		RoamCount:       sta.RoamCount,
 		SiteID:          sta.SiteID,
 		Stats: &StationStats{
@@ -102,58 +487,123 @@ func (s *Station) UnmarshalJSON(b []byte) error {
 			TransmitPackets: sta.TxPackets,
 			TransmitPower:   sta.TxPower,
 			TransmitRate:    sta.TxRate,
+
+			TransmitAttempts: sta.WifiTxAttempts,
+			TransmitRetries:  sta.TxRetries,
+			TransmitFailed:   sta.TxFailed,
+
+			ReceiveRateBytes:  sta.RxBytesR,
+			TransmitRateBytes: sta.TxBytesR,
+			TotalRateBytes:    sta.BytesR,
 		},
-		Uptime: time.Duration(time.Duration(sta.Uptime) * time.Second),
-		UserID: sta.UserID,
+		Uptime:     time.Duration(time.Duration(uptime) * time.Second),
+		UseFixedIP: sta.UseFixedIP,
+		UserID:     sta.UserID,
+
+		SwitchMAC:  swMAC,
+		SwitchPort: swPort,
+
+		Anomalies: anomalies,
 	}
 
 	return nil
 }
 
+// parseAnomalies parses the raw anomalies field of a Station.  Depending on
+// the UniFi Controller version, anomalies are reported either as a list of
+// descriptions or as a bitfield.  The meaning of each bit is not documented
+// by Ubiquiti, so every set bit is described as "unknown(<bit>)".  A missing
+// or negative field means that no anomalies were computed, and results in a
+// nil slice.
+func parseAnomalies(b json.RawMessage) ([]string, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+
+	if b[0] == '[' {
+		var ss []string
+		if err := json.Unmarshal(b, &ss); err != nil {
+			return nil, err
+		}
+
+		return ss, nil
+	}
+
+	var bits int64
+	if err := json.Unmarshal(b, &bits); err != nil {
+		return nil, err
+	}
+	if bits < 0 {
+		return nil, nil
+	}
+
+	anomalies := make([]string, 0)
+	for i := 0; i < 63; i++ {
+		if bits&(1<<i) == 0 {
+			continue
+		}
+
+		anomalies = append(anomalies, fmt.Sprintf("unknown(%d)", i))
+	}
+
+	return anomalies, nil
+}
+
 // A station is the raw structure of a Station returned from the UniFi Controller
 // API.
 type station struct {
 	// TODO(mdlayher): give all fields appropriate names and data types.
-	ID               string `json:"_id"`
-	IsGuestByUap     bool   `json:"_is_guest_by_uap"`
-	LastSeenByUap    int    `json:"_last_seen_by_uap"`
-	UptimeByUap      int    `json:"_uptime_by_uap"`
-	ApMac            string `json:"ap_mac"`
-	AssocTime        int    `json:"assoc_time"`
-	Authorized       bool   `json:"authorized"`
-	Bssid            string `json:"bssid"`
-	BytesR           int64  `json:"bytes-r"`
-	Ccq              int    `json:"ccq"`
-	Channel          int    `json:"channel"`
-	Essid            string `json:"essid"`
-	FirstSeen        int    `json:"first_seen"`
-	Hostname         string `json:"hostname"`
-	Idletime         int    `json:"idletime"`
-	IP               string `json:"ip"`
-	IsGuest          bool   `json:"is_guest"`
-	IsWired          bool   `json:"is_wired"`
-	LastSeen         int    `json:"last_seen"`
-	Mac              string `json:"mac"`
-	Name             string `json:"name"`
-	Noise            int    `json:"noise"`
-	Oui              string `json:"oui"`
-	PowersaveEnabled bool   `json:"powersave_enabled"`
-	QosPolicyApplied bool   `json:"qos_policy_applied"`
-	Radio            string `json:"radio"`
-	RadioProto       string `json:"radio_proto"`
-	RoamCount        int    `json:"roam_count"`
-	RSSI             int    `json:"rssi"`
-	RxBytes          int64  `json:"rx_bytes"`
-	RxBytesR         int64  `json:"rx_bytes-r"`
-	RxPackets        int64  `json:"rx_packets"`
-	RxRate           int    `json:"rx_rate"`
-	Signal           int    `json:"signal"`
-	SiteID           string `json:"site_id"`
-	TxBytes          int64  `json:"tx_bytes"`
-	TxBytesR         int64  `json:"tx_bytes-r"`
-	TxPackets        int64  `json:"tx_packets"`
-	TxPower          int    `json:"tx_power"`
-	TxRate           int    `json:"tx_rate"`
-	Uptime           int    `json:"uptime"`
-	UserID           string `json:"user_id"`
+	ID               string          `json:"_id"`
+	Anomalies        json.RawMessage `json:"anomalies"`
+	IsGuestByUap     bool            `json:"_is_guest_by_uap"`
+	LastSeenByUap    int             `json:"_last_seen_by_uap"`
+	UptimeByUap      int             `json:"_uptime_by_uap"`
+	UptimeByUgw      int             `json:"_uptime_by_ugw"`
+	UptimeByUsw      int             `json:"_uptime_by_usw"`
+	ApMac            string          `json:"ap_mac"`
+	AssocTime        int             `json:"assoc_time"`
+	Authorized       bool            `json:"authorized"`
+	Bssid            string          `json:"bssid"`
+	BytesR           int64           `json:"bytes-r"`
+	Ccq              int             `json:"ccq"`
+	Channel          int             `json:"channel"`
+	Essid            string          `json:"essid"`
+	FirstSeen        int             `json:"first_seen"`
+	FixedIP          string          `json:"fixed_ip"`
+	Hostname         string          `json:"hostname"`
+	Idletime         int             `json:"idletime"`
+	IP               string          `json:"ip"`
+	IsGuest          bool            `json:"is_guest"`
+	IsWired          bool            `json:"is_wired"`
+	LastSeen         int             `json:"last_seen"`
+	Mac              string          `json:"mac"`
+	Name             string          `json:"name"`
+	NetworkID        string          `json:"network_id"`
+	Noise            int             `json:"noise"`
+	Oui              string          `json:"oui"`
+	PowersaveEnabled bool            `json:"powersave_enabled"`
+	QosPolicyApplied bool            `json:"qos_policy_applied"`
+	Radio            string          `json:"radio"`
+	RadioProto       string          `json:"radio_proto"`
+	RoamCount        int             `json:"roam_count"`
+	RSSI             int             `json:"rssi"`
+	RxBytes          int64           `json:"rx_bytes"`
+	RxBytesR         int64           `json:"rx_bytes-r"`
+	RxPackets        int64           `json:"rx_packets"`
+	RxRate           int             `json:"rx_rate"`
+	Signal           int             `json:"signal"`
+	SiteID           string          `json:"site_id"`
+	SwMac            string          `json:"sw_mac"`
+	SwPort           int             `json:"sw_port"`
+	TxBytes          int64           `json:"tx_bytes"`
+	TxBytesR         int64           `json:"tx_bytes-r"`
+	TxFailed         int64           `json:"tx_failed"`
+	TxPackets        int64           `json:"tx_packets"`
+	TxPower          int             `json:"tx_power"`
+	TxRate           int             `json:"tx_rate"`
+	TxRetries        int64           `json:"tx_retries"`
+	Uptime           int             `json:"uptime"`
+	UseFixedIP       bool            `json:"use_fixedip"`
+	UserID           string          `json:"user_id"`
+	WifiTxAttempts   int64           `json:"wifi_tx_attempts"`
 }
@@ -2,6 +2,7 @@ package unifi
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -27,28 +28,48 @@ func TestClientStations(t *testing.T) {
 	zeroUNIX := time.Unix(0, 0)
 
 	wantStation := &Station{
-		ID:              wantID,
-		APMAC:           wantStationMAC,
-		AssociationTime: zeroUNIX,
-		FirstSeen:       zeroUNIX,
-		Hostname:        wantHostname,
-		IP:              wantIP,
-		LastSeen:        zeroUNIX,
-		MAC:             wantMAC,
-		SiteID:          wantSite,
-		Stats:           &StationStats{},
+		ID:         wantID,
+		APMAC:      wantStationMAC,
+		Authorized: true,
+		BSSID:      "de:ad:be:ef:de:ae",
+		CCQ:        991,
+		ESSID:      "guest",
+		FirstSeen:  zeroUNIX,
+		Hostname:   wantHostname,
+		IP:         wantIP,
+		IsGuest:    true,
+		LastSeen:   zeroUNIX,
+		MAC:        wantMAC,
+		RadioProto: "ac",
+		Signal:     -52,
+		SiteID:     wantSite,
+		Stats: &StationStats{
+			ReceiveRateBytes:  1500,
+			TransmitRateBytes: 500,
+			TotalRateBytes:    2000,
+		},
 	}
 
 	v := struct {
 		Stations []station `json:"data"`
 	}{
 		Stations: []station{{
-			ID:       wantID,
-			ApMac:    wantStationMAC.String(),
-			Hostname: wantHostname,
-			IP:       wantIP.String(),
-			Mac:      wantMAC.String(),
-			SiteID:   wantSite,
+			ID:         wantID,
+			ApMac:      wantStationMAC.String(),
+			Authorized: true,
+			Bssid:      "de:ad:be:ef:de:ae",
+			Ccq:        991,
+			Essid:      "guest",
+			Hostname:   wantHostname,
+			IP:         wantIP.String(),
+			IsGuest:    true,
+			Mac:        wantMAC.String(),
+			RadioProto: "ac",
+			Signal:     -52,
+			SiteID:     wantSite,
+			RxBytesR:   1500,
+			TxBytesR:   500,
+			BytesR:     2000,
 		}},
 	}
 
@@ -129,21 +150,20 @@ func TestStationUnmarshalJSON(t *testing.T) {
 }
 `)),
 			s: &Station{
-				ID:              "abcdef1234567890",
-				APMAC:           net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
-				AssociationTime: zeroUNIX,
-				Channel:         1,
-				FirstSeen:       zeroUNIX,
-				Hostname:        "somehost",
-				IP:              net.IPv4(192, 168, 1, 2),
-				IsWired:         true,
-				LastSeen:        zeroUNIX,
-				MAC:             net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
-				Name:            "somename",
-				Noise:           -110,
-				RoamCount:       1,
-				RSSI:            40,
-				SiteID:          "somesite",
+				ID:        "abcdef1234567890",
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				Channel:   1,
+				FirstSeen: zeroUNIX,
+				Hostname:  "somehost",
+				IP:        net.IPv4(192, 168, 1, 2),
+				IsWired:   true,
+				LastSeen:  zeroUNIX,
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Name:      "somename",
+				Noise:     -110,
+				RoamCount: 1,
+				RSSI:      40,
+				SiteID:    "somesite",
 				Stats: &StationStats{
 					ReceiveBytes:    80,
 					ReceivePackets:  4,
@@ -157,6 +177,173 @@ func TestStationUnmarshalJSON(t *testing.T) {
 				UserID: "someuser",
 			},
 		},
+		{
+			desc: "OK wired",
+			b: bytes.TrimSpace([]byte(`
+{
+	"_id": "abcdef1234567890",
+	"_uptime_by_usw": 3600,
+	"first_seen": 1451606400,
+	"ip": "192.168.1.3",
+	"is_wired": true,
+	"last_seen": 1451610000,
+	"mac": "de:ad:be:ef:de:ae",
+	"sw_mac": "ab:ad:1d:ea:ab:ae",
+	"sw_port": 4
+}
+`)),
+			s: &Station{
+				ID:        "abcdef1234567890",
+				FirstSeen: time.Unix(1451606400, 0),
+				IP:        net.IPv4(192, 168, 1, 3),
+				IsWired:   true,
+				LastSeen:  time.Unix(1451610000, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xae},
+				Stats:     &StationStats{},
+				Uptime:    time.Hour,
+
+				SwitchMAC:  net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xae},
+				SwitchPort: 4,
+			},
+		},
+		{
+			desc: "invalid switch MAC",
+			b:    []byte(`{"is_wired":true,"mac":"de:ad:be:ef:de:ae","sw_mac":"foo"}`),
+			err:  errors.New("invalid MAC address"),
+		},
+		{
+			desc: "OK wireless switch location",
+			b: bytes.TrimSpace([]byte(`
+{
+	"ap_mac": "ab:ad:1d:ea:ab:ad",
+	"mac": "de:ad:be:ef:de:ad",
+	"sw_mac": "ab:ad:1d:ea:ab:ae",
+	"sw_port": 4
+}
+`)),
+			s: &Station{
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Stats:     &StationStats{},
+			},
+		},
+		{
+			desc: "OK fixed IP",
+			b: bytes.TrimSpace([]byte(`
+{
+	"ap_mac": "ab:ad:1d:ea:ab:ad",
+	"fixed_ip": "192.168.1.50",
+	"mac": "de:ad:be:ef:de:ad",
+	"network_id": "somenetwork",
+	"use_fixedip": true
+}
+`)),
+			s: &Station{
+				APMAC:      net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen:  time.Unix(0, 0),
+				FixedIP:    net.IPv4(192, 168, 1, 50),
+				LastSeen:   time.Unix(0, 0),
+				MAC:        net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				NetworkID:  "somenetwork",
+				Stats:      &StationStats{},
+				UseFixedIP: true,
+			},
+		},
+		{
+			desc: "OK fixed IP not in use",
+			b: bytes.TrimSpace([]byte(`
+{
+	"ap_mac": "ab:ad:1d:ea:ab:ad",
+	"fixed_ip": "192.168.1.50",
+	"mac": "de:ad:be:ef:de:ad",
+	"use_fixedip": false
+}
+`)),
+			s: &Station{
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Stats:     &StationStats{},
+			},
+		},
+		{
+			desc: "OK anomalies bitfield",
+			b:    []byte(`{"ap_mac":"ab:ad:1d:ea:ab:ad","mac":"de:ad:be:ef:de:ad","anomalies":37}`),
+			s: &Station{
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Stats:     &StationStats{},
+				Anomalies: []string{"unknown(0)", "unknown(2)", "unknown(5)"},
+			},
+		},
+		{
+			desc: "OK no anomalies",
+			b:    []byte(`{"ap_mac":"ab:ad:1d:ea:ab:ad","mac":"de:ad:be:ef:de:ad","anomalies":0}`),
+			s: &Station{
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Stats:     &StationStats{},
+				Anomalies: []string{},
+			},
+		},
+		{
+			desc: "OK anomalies not computed",
+			b:    []byte(`{"ap_mac":"ab:ad:1d:ea:ab:ad","mac":"de:ad:be:ef:de:ad","anomalies":-1}`),
+			s: &Station{
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Stats:     &StationStats{},
+			},
+		},
+		{
+			desc: "OK anomalies list",
+			b:    []byte(`{"ap_mac":"ab:ad:1d:ea:ab:ad","mac":"de:ad:be:ef:de:ad","anomalies":["sleepy client"]}`),
+			s: &Station{
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Stats:     &StationStats{},
+				Anomalies: []string{"sleepy client"},
+			},
+		},
+		{
+			desc: "invalid anomalies",
+			b:    []byte(`{"ap_mac":"ab:ad:1d:ea:ab:ad","mac":"de:ad:be:ef:de:ad","anomalies":"foo"}`),
+			err:  errors.New("cannot unmarshal"),
+		},
+		{
+			desc: "OK wireless retries",
+			b: bytes.TrimSpace([]byte(`
+{
+	"ap_mac": "ab:ad:1d:ea:ab:ad",
+	"mac": "de:ad:be:ef:de:ad",
+	"tx_failed": 2,
+	"tx_retries": 30,
+	"wifi_tx_attempts": 300
+}
+`)),
+			s: &Station{
+				APMAC:     net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad},
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Stats: &StationStats{
+					TransmitAttempts: 300,
+					TransmitRetries:  30,
+					TransmitFailed:   2,
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,3 +365,653 @@ func TestStationUnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestClientStationAP(t *testing.T) {
+	const wantSite = "default"
+	var (
+		wiredMAC    = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0x01}
+		wirelessMAC = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0x02}
+		orphanMAC   = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0x03}
+		apMAC       = net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0x01}
+		goneAPMAC   = net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0x02}
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var v interface{}
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/s/%s/stat/device", wantSite):
+			v = struct {
+				Devices []device `json:"data"`
+			}{
+				Devices: []device{{
+					InformIP: "192.168.1.1",
+					MAC:      apMAC.String(),
+					Name:     "ap001",
+				}},
+			}
+		default:
+			v = stationLookup(r.URL.Path, fmt.Sprintf("/api/s/%s/stat/sta/", wantSite), []station{
+				{Mac: wiredMAC.String(), IsWired: true},
+				{Mac: wirelessMAC.String(), ApMac: apMAC.String()},
+				{Mac: orphanMAC.String(), ApMac: goneAPMAC.String()},
+			})
+		}
+
+		testHandler(t, http.MethodGet, r.URL.Path, nil, v)(w, r)
+	})
+	defer done()
+
+	var tests = []struct {
+		desc string
+		mac  string
+		name string
+		err  error
+	}{
+		{
+			desc: "not connected",
+			mac:  "ff:ff:ff:ff:ff:ff",
+			err:  ErrNotFound,
+		},
+		{
+			desc: "wired",
+			mac:  wiredMAC.String(),
+			err:  ErrNotFound,
+		},
+		{
+			desc: "unknown access point",
+			mac:  orphanMAC.String(),
+			err:  ErrNotFound,
+		},
+		{
+			desc: "OK",
+			mac:  wirelessMAC.String(),
+			name: "ap001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d, err := c.StationAP(wantSite, tt.mac)
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if err != nil {
+				return
+			}
+
+			if want, got := tt.name, d.Name; want != got {
+				t.Fatalf("unexpected Device name:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientTopTalkers(t *testing.T) {
+	const wantSite = "default"
+
+	stations := []station{
+		{Mac: "de:ad:be:ef:de:01", IsWired: true, RxBytes: 10, TxBytes: 10},
+		{Mac: "de:ad:be:ef:de:04", IsWired: true, RxBytes: 5, TxBytes: 50},
+		{Mac: "de:ad:be:ef:de:03", IsWired: true, RxBytes: 20, TxBytes: 0},
+		{Mac: "de:ad:be:ef:de:02", IsWired: true, RxBytes: 100, TxBytes: 0},
+	}
+
+	var tests = []struct {
+		desc string
+		n    int
+		macs []string
+	}{
+		{
+			desc: "none",
+			n:    0,
+		},
+		{
+			desc: "top two",
+			n:    2,
+			macs: []string{"de:ad:be:ef:de:02", "de:ad:be:ef:de:04"},
+		},
+		{
+			desc: "ties ordered by MAC",
+			n:    4,
+			macs: []string{
+				"de:ad:be:ef:de:02",
+				"de:ad:be:ef:de:04",
+				"de:ad:be:ef:de:01",
+				"de:ad:be:ef:de:03",
+			},
+		},
+		{
+			desc: "more than available",
+			n:    10,
+			macs: []string{
+				"de:ad:be:ef:de:02",
+				"de:ad:be:ef:de:04",
+				"de:ad:be:ef:de:01",
+				"de:ad:be:ef:de:03",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := struct {
+				Stations []station `json:"data"`
+			}{
+				Stations: stations,
+			}
+
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/stat/sta", wantSite),
+				nil,
+				v,
+			))
+			defer done()
+
+			top, err := c.TopTalkers(wantSite, tt.n)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.TopTalkers: %v", err)
+			}
+
+			var macs []string
+			for _, s := range top {
+				macs = append(macs, s.MAC.String())
+			}
+
+			if want, got := tt.macs, macs; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected Stations:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientWaitForStation(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantMAC  = "de:ad:be:ef:de:ad"
+	)
+
+	present := []station{{
+		ApMac: "ab:ad:1d:ea:ab:ad",
+		Mac:   wantMAC,
+	}}
+
+	var tests = []struct {
+		desc      string
+		polls     [][]station
+		connected bool
+		err       error
+	}{
+		{
+			desc:      "connects",
+			polls:     [][]station{nil, nil, present},
+			connected: true,
+		},
+		{
+			desc:      "disconnects",
+			polls:     [][]station{present, nil},
+			connected: false,
+		},
+		{
+			desc:      "already connected",
+			polls:     [][]station{present},
+			connected: true,
+		},
+		{
+			desc:      "timeout",
+			polls:     [][]station{nil},
+			connected: true,
+			err:       context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var i int
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				stations := tt.polls[len(tt.polls)-1]
+				if i < len(tt.polls) {
+					stations = tt.polls[i]
+				}
+				i++
+
+				testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/stat/sta", wantSite), nil,
+					struct {
+						Stations []station `json:"data"`
+					}{Stations: stations},
+				)(w, r)
+			})
+			defer done()
+			c.pollInterval = 5 * time.Millisecond
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			err := c.WaitForStation(ctx, wantSite, strings.ToUpper(wantMAC), tt.connected)
+			if want, got := tt.err, err; want != got {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientIPConflicts(t *testing.T) {
+	const wantSite = "default"
+
+	stations := []station{
+		{Mac: "de:ad:be:ef:de:01", IsWired: true, IP: "192.168.1.5"},
+		{Mac: "de:ad:be:ef:de:02", IsWired: true, IP: "::ffff:192.168.1.5"},
+		{Mac: "de:ad:be:ef:de:03", IsWired: true, IP: "192.168.1.6"},
+		{Mac: "de:ad:be:ef:de:04", IsWired: true},
+		{Mac: "de:ad:be:ef:de:05", IsWired: true},
+		{Mac: "de:ad:be:ef:de:06", IsWired: true, IP: "192.168.1.7"},
+		{Mac: "de:ad:be:ef:de:06", IsWired: true, IP: "192.168.1.7"},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/stat/sta", wantSite),
+		nil,
+		struct {
+			Stations []station `json:"data"`
+		}{Stations: stations},
+	))
+	defer done()
+
+	conflicts, err := c.IPConflicts(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.IPConflicts: %v", err)
+	}
+
+	macs := make(map[string][]string)
+	for ip, ss := range conflicts {
+		for _, s := range ss {
+			macs[ip] = append(macs[ip], s.MAC.String())
+		}
+	}
+
+	want := map[string][]string{
+		"192.168.1.5": {"de:ad:be:ef:de:01", "de:ad:be:ef:de:02"},
+	}
+
+	if got := macs; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected IP conflicts:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientStationWLAN(t *testing.T) {
+	const wantSite = "default"
+	var (
+		hiddenMAC = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0x01}
+		guestMAC  = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0x02}
+		caseMAC   = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0x03}
+		apMAC     = net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0x01}
+	)
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var v interface{}
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/s/%s/rest/wlanconf", wantSite):
+			v = struct {
+				WLANs []*WLAN `json:"data"`
+			}{
+				WLANs: []*WLAN{
+					{ID: "corp", Name: "Corp"},
+					{ID: "guest", Name: "Guest", IsGuest: true},
+				},
+			}
+		default:
+			v = stationLookup(r.URL.Path, fmt.Sprintf("/api/s/%s/stat/sta/", wantSite), []station{
+				{Mac: hiddenMAC.String(), ApMac: apMAC.String()},
+				{Mac: guestMAC.String(), ApMac: apMAC.String(), Essid: "Guest"},
+				{Mac: caseMAC.String(), ApMac: apMAC.String(), Essid: "guest"},
+			})
+		}
+
+		testHandler(t, http.MethodGet, r.URL.Path, nil, v)(w, r)
+	})
+	defer done()
+
+	var tests = []struct {
+		desc string
+		mac  string
+		id   string
+		err  error
+	}{
+		{
+			desc: "not connected",
+			mac:  "ff:ff:ff:ff:ff:ff",
+			err:  ErrNotFound,
+		},
+		{
+			desc: "hidden ESSID",
+			mac:  hiddenMAC.String(),
+			err:  ErrNotFound,
+		},
+		{
+			desc: "case mismatch",
+			mac:  caseMAC.String(),
+			err:  ErrNotFound,
+		},
+		{
+			desc: "OK",
+			mac:  guestMAC.String(),
+			id:   "guest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			wlan, err := c.StationWLAN(wantSite, tt.mac)
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if err != nil {
+				return
+			}
+
+			if want, got := tt.id, wlan.ID; want != got {
+				t.Fatalf("unexpected WLAN ID:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientAPLoadDistribution(t *testing.T) {
+	const wantSite = "default"
+	var (
+		ap1MAC = net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0x01}
+		ap2MAC = net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0x02}
+		ap3MAC = net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0x03}
+		swMAC  = net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0x04}
+	)
+
+	devices := []device{
+		{InformIP: "192.168.1.1", MAC: ap1MAC.String(), Type: "uap"},
+		{InformIP: "192.168.1.1", MAC: ap2MAC.String(), Type: "uap"},
+		{InformIP: "192.168.1.1", MAC: ap3MAC.String(), Type: "uap"},
+		{InformIP: "192.168.1.1", MAC: swMAC.String(), Type: "usw"},
+	}
+
+	var tests = []struct {
+		desc     string
+		stations []station
+		load     map[string]float64
+	}{
+		{
+			desc: "no stations",
+			load: map[string]float64{},
+		},
+		{
+			desc: "only wired",
+			stations: []station{
+				{Mac: "de:ad:be:ef:de:01", IsWired: true},
+			},
+			load: map[string]float64{},
+		},
+		{
+			desc: "OK",
+			stations: []station{
+				{Mac: "de:ad:be:ef:de:01", IsWired: true},
+				{Mac: "de:ad:be:ef:de:02", ApMac: ap1MAC.String()},
+				{Mac: "de:ad:be:ef:de:03", ApMac: ap1MAC.String()},
+				{Mac: "de:ad:be:ef:de:04", ApMac: ap1MAC.String()},
+				{Mac: "de:ad:be:ef:de:05", ApMac: ap2MAC.String()},
+			},
+			load: map[string]float64{
+				ap1MAC.String(): 0.75,
+				ap2MAC.String(): 0.25,
+				ap3MAC.String(): 0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				var v interface{}
+				switch r.URL.Path {
+				case fmt.Sprintf("/api/s/%s/stat/sta", wantSite):
+					v = struct {
+						Stations []station `json:"data"`
+					}{Stations: tt.stations}
+				case fmt.Sprintf("/api/s/%s/stat/device", wantSite):
+					v = struct {
+						Devices []device `json:"data"`
+					}{Devices: devices}
+				}
+
+				testHandler(t, http.MethodGet, r.URL.Path, nil, v)(w, r)
+			})
+			defer done()
+
+			load, err := c.APLoadDistribution(wantSite)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.APLoadDistribution: %v", err)
+			}
+
+			if want, got := tt.load, load; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected AP load distribution:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientSignalDistribution(t *testing.T) {
+	const wantSite = "default"
+
+	var tests = []struct {
+		desc     string
+		stations []station
+		dist     map[string]int
+	}{
+		{
+			desc: "no stations",
+			dist: map[string]int{
+				"excellent": 0,
+				"good":      0,
+				"fair":      0,
+				"poor":      0,
+			},
+		},
+		{
+			desc: "OK",
+			stations: []station{
+				{Mac: "de:ad:be:ef:de:01", IsWired: true},
+				{Mac: "de:ad:be:ef:de:02", ApMac: "ab:ad:1d:ea:ab:01", RSSI: 60},
+				{Mac: "de:ad:be:ef:de:03", ApMac: "ab:ad:1d:ea:ab:01", RSSI: 45},
+				{Mac: "de:ad:be:ef:de:04", ApMac: "ab:ad:1d:ea:ab:01", RSSI: 44},
+				{Mac: "de:ad:be:ef:de:05", ApMac: "ab:ad:1d:ea:ab:01", RSSI: 25},
+				{Mac: "de:ad:be:ef:de:06", ApMac: "ab:ad:1d:ea:ab:01", RSSI: 24},
+			},
+			dist: map[string]int{
+				"excellent": 2,
+				"good":      1,
+				"fair":      1,
+				"poor":      1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/stat/sta", wantSite),
+				nil,
+				struct {
+					Stations []station `json:"data"`
+				}{Stations: tt.stations},
+			))
+			defer done()
+
+			dist, err := c.SignalDistribution(wantSite)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.SignalDistribution: %v", err)
+			}
+
+			if want, got := tt.dist, dist; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected signal distribution:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestStationRandomizedMAC(t *testing.T) {
+	tests := []struct {
+		name string
+		mac  net.HardwareAddr
+		ok   bool
+	}{
+		{
+			name: "OUI registered",
+			mac:  net.HardwareAddr{0x00, 0x27, 0x22, 0xde, 0xad, 0xbe},
+		},
+		{
+			name: "OUI registered multicast",
+			mac:  net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0xfb},
+		},
+		{
+			name: "randomized",
+			mac:  net.HardwareAddr{0xda, 0xa1, 0x19, 0x12, 0x34, 0x56},
+			ok:   true,
+		},
+		{
+			name: "randomized multicast",
+			mac:  net.HardwareAddr{0x03, 0x00, 0x00, 0x00, 0x00, 0x01},
+			ok:   true,
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Station{MAC: tt.mac}
+			if want, got := tt.ok, s.RandomizedMAC(); want != got {
+				t.Fatalf("unexpected randomized MAC for %q:\n- want: %v\n-  got: %v",
+					tt.mac, want, got)
+			}
+		})
+	}
+}
+
+func TestClientStationByMAC(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	mac := net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad}
+
+	var tests = []struct {
+		desc     string
+		stations []station
+		err      error
+	}{
+		{
+			desc:     "not connected",
+			stations: []station{},
+			err:      ErrNotFound,
+		},
+		{
+			desc: "OK",
+			stations: []station{{
+				ID:     wantID,
+				ApMac:  "de:ad:be:ef:de:ad",
+				Mac:    mac.String(),
+				RSSI:   40,
+				Uptime: 60,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/stat/sta/ab:ad:1d:ea:ab:ad", wantSite),
+				nil,
+				struct {
+					Stations []station `json:"data"`
+				}{Stations: tt.stations},
+			))
+			defer done()
+
+			s, err := c.StationByMAC(wantSite, mac)
+			if want, got := tt.err, err; !errors.Is(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if err != nil {
+				return
+			}
+
+			if want, got := wantID, s.ID; want != got {
+				t.Fatalf("unexpected Station ID:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := mac, s.MAC; !bytes.Equal(want, got) {
+				t.Fatalf("unexpected Station MAC:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := 40, s.RSSI; want != got {
+				t.Fatalf("unexpected Station RSSI:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientStationByMACDisconnected(t *testing.T) {
+	const wantSite = "default"
+
+	mac := net.HardwareAddr{0xab, 0xad, 0x1d, 0xea, 0xab, 0xad}
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/s/%s/stat/sta/%s", wantSite, mac):
+			// The client is no longer connected.
+			testHandler(t, http.MethodGet, r.URL.Path, nil, struct {
+				Stations []station `json:"data"`
+			}{Stations: []station{}})(w, r)
+		default:
+			// A stored client record, such as from stat/user/<mac>, must
+			// not be mistaken for a connected Station.
+			t.Fatalf("unexpected URL path: %v", r.URL.Path)
+		}
+	})
+	defer done()
+
+	if _, err := c.StationByMAC(wantSite, mac); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrNotFound, err)
+	}
+}
+
+// stationLookup serves a single-client lookup of the form prefix/<mac> from
+// stations, as the UniFi Controller does for stat/sta/<mac>: a known MAC
+// address produces a one-element list, and an unknown one an empty list.
+func stationLookup(path, prefix string, stations []station) interface{} {
+	out := []station{}
+	if mac := strings.TrimPrefix(path, prefix); mac != path {
+		for _, s := range stations {
+			if s.Mac == mac {
+				out = append(out, s)
+			}
+		}
+	}
+
+	return struct {
+		Stations []station `json:"data"`
+	}{
+		Stations: out,
+	}
+}
@@ -0,0 +1,91 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// SysInfo contains information about a UniFi Controller and the system it
+// runs on.
+type SysInfo struct {
+	Build           string   `json:"build"`
+	Hostname        string   `json:"hostname"`
+	IPAddresses     []string `json:"ip_addrs"`
+	Name            string   `json:"name"`
+	Timezone        string   `json:"timezone"`
+	UpdateAvailable bool     `json:"update_available"`
+	Version         string   `json:"version"`
+}
+
+// SysInfo returns information about the UniFi Controller from the perspective
+// of a specified site name.
+func (c *Client) SysInfo(siteName string) (*SysInfo, error) {
+	return getOne[SysInfo](context.Background(), c, siteName, "stat/sysinfo")
+}
+
+// RebootController reboots the UniFi OS console which runs the UniFi
+// Controller, interrupting all of the services it provides until it has
+// restarted.  This differs from restarting a Device managed by the UniFi
+// Controller.
+//
+// Because rebooting the console is so disruptive, the Client's AllowReboot
+// field must be set, or an error is returned.  Classic UniFi Controllers
+// cannot be rebooted through the API, so unless the Client's UniFiOS field
+// is set, an error wrapping ErrUnsupported is returned.
+func (c *Client) RebootController() error {
+	if !c.AllowReboot {
+		return errors.New("rebooting the controller requires Client.AllowReboot to be set")
+	}
+	if !c.isUniFiOS() {
+		return fmt.Errorf("rebooting the controller requires a UniFi OS console: %w", ErrUnsupported)
+	}
+
+	req, err := c.newRequest(http.MethodPost, "/api/system/reboot", nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
+
+// ControllerStatus contains the status of a UniFi Controller, as reported
+// without authentication.
+type ControllerStatus struct {
+	Up            bool
+	ServerVersion string
+	UUID          string
+}
+
+// Status returns the status of the UniFi Controller.  Status does not require
+// Client.Login to be called first, so it may be used to check that the UniFi
+// Controller is ready before logging in.
+func (c *Client) Status(ctx context.Context) (*ControllerStatus, error) {
+	var v struct {
+		Meta struct {
+			RC            string `json:"rc"`
+			Up            bool   `json:"up"`
+			ServerVersion string `json:"server_version"`
+			UUID          string `json:"uuid"`
+		} `json:"meta"`
+		Data json.RawMessage `json:"data"`
+	}
+
+	req, err := c.newRequest(http.MethodGet, "/status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req.WithContext(ctx), &v); err != nil {
+		return nil, err
+	}
+
+	return &ControllerStatus{
+		Up:            v.Meta.Up,
+		ServerVersion: v.Meta.ServerVersion,
+		UUID:          v.Meta.UUID,
+	}, nil
+}
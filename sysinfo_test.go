@@ -0,0 +1,163 @@
+package unifi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientSysInfo(t *testing.T) {
+	const wantSite = "default"
+
+	wantInfo := &SysInfo{
+		Build:       "atag_5.6.29_10253",
+		Hostname:    "unifi",
+		IPAddresses: []string{"192.168.1.2"},
+		Timezone:    "UTC",
+		Version:     "5.6.29",
+	}
+
+	var tests = []struct {
+		desc string
+		data interface{}
+		info *SysInfo
+		err  error
+	}{
+		{
+			desc: "array",
+			data: []*SysInfo{wantInfo},
+			info: wantInfo,
+		},
+		{
+			desc: "object",
+			data: wantInfo,
+			info: wantInfo,
+		},
+		{
+			desc: "empty array",
+			data: []*SysInfo{},
+			err:  ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/stat/sysinfo", wantSite),
+				nil,
+				map[string]interface{}{"data": tt.data},
+			))
+			defer done()
+
+			info, err := c.SysInfo(wantSite)
+			if want, got := tt.err, err; want != got {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+			}
+			if err != nil {
+				return
+			}
+
+			if want, got := tt.info, info; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected SysInfo:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientRebootController(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		allow   bool
+		unifiOS bool
+		err     error
+	}{
+		{
+			desc:    "not allowed",
+			unifiOS: true,
+			err:     errors.New("requires Client.AllowReboot"),
+		},
+		{
+			desc:  "classic controller",
+			allow: true,
+			err:   ErrUnsupported,
+		},
+		{
+			desc:    "OK",
+			allow:   true,
+			unifiOS: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var rebooted bool
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if want, got := http.MethodPost, r.Method; want != got {
+					t.Fatalf("unexpected HTTP method:\n- want: %v\n-  got: %v", want, got)
+				}
+				if want, got := "/api/system/reboot", r.URL.Path; want != got {
+					t.Fatalf("unexpected URL path:\n- want: %v\n-  got: %v", want, got)
+				}
+
+				rebooted = true
+				w.Header().Set("Content-Type", jsonContentType)
+				_, _ = w.Write([]byte(`{}`))
+			})
+			defer done()
+
+			c.AllowReboot = tt.allow
+			c.UniFiOS = tt.unifiOS
+
+			err := c.RebootController()
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error from Client.RebootController: %v", err)
+			}
+
+			if want, got := tt.err == nil, rebooted; want != got {
+				t.Fatalf("unexpected reboot:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientStatus(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict %t", strict), func(t *testing.T) {
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if want, got := "/status", r.URL.Path; want != got {
+					t.Fatalf("unexpected URL path:\n- want: %v\n-  got: %v", want, got)
+				}
+
+				w.Header().Set("Content-Type", jsonContentType)
+				_, _ = w.Write([]byte(`{"meta":{"rc":"ok","up":true,"server_version":"5.6.29","uuid":"abcdef"},"data":[]}`))
+			})
+			defer done()
+			c.StrictJSON = strict
+
+			s, err := c.Status(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error from Client.Status: %v", err)
+			}
+
+			want := &ControllerStatus{
+				Up:            true,
+				ServerVersion: "5.6.29",
+				UUID:          "abcdef",
+			}
+
+			if got := s; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected ControllerStatus:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,520 @@
+package unifi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"time"
+)
+
+// A SortOrder specifies the order in which results are sorted.
+type SortOrder int
+
+// List of possible SortOrder values.
+const (
+	SortDefault SortOrder = iota
+	SortAscending
+	SortDescending
+)
+
+// AllUsersOptions specifies optional parameters for Client.AllUsers.
+type AllUsersOptions struct {
+	// Within, if set, limits results to Users seen within the specified
+	// duration.  The duration is rounded up to the nearest hour.
+	Within time.Duration
+
+	// Start and Limit, if set, specify the offset of the first User and the
+	// maximum number of Users to return.
+	Start int
+	Limit int
+
+	// Sort, if set, sorts Users by the time they were last seen.
+	Sort SortOrder
+}
+
+// A UsersPage is a page of results returned by Client.AllUsers.
+type UsersPage struct {
+	Users []*User
+
+	// Total is the total number of Users matching the query across all
+	// pages, or 0 if the UniFi Controller did not report a total.
+	Total int
+
+	// More reports whether additional pages of Users may remain.
+	More bool
+}
+
+// AllUsers returns a page of Users which have ever connected to a specified
+// site name, using optional parameters to select the page.  If opts is nil,
+// all Users are returned.
+func (c *Client) AllUsers(siteName string, opts *AllUsersOptions) (*UsersPage, error) {
+	if opts == nil {
+		opts = &AllUsersOptions{}
+	}
+
+	q := &userQuery{
+		Type:  "all",
+		Conn:  "all",
+		Start: opts.Start,
+		Limit: opts.Limit,
+	}
+
+	if opts.Within > 0 {
+		q.Within = int(math.Ceil(opts.Within.Hours()))
+	}
+
+	switch opts.Sort {
+	case SortAscending:
+		q.Sort = "last_seen"
+	case SortDescending:
+		q.Sort = "-last_seen"
+	}
+
+	var v struct {
+		Meta  pageMeta `json:"meta"`
+		Users []*User  `json:"data"`
+	}
+
+	req, err := c.newRequest(
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/stat/alluser", siteName),
+		q,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.do(req, &v); err != nil {
+		return nil, err
+	}
+
+	// Prefer the total reported by the UniFi Controller, but fall back to
+	// assuming that a full page may be followed by another.
+	total := v.Meta.total()
+	more := opts.Limit > 0 && len(v.Users) == opts.Limit
+	if total > 0 {
+		more = opts.Start+len(v.Users) < total
+	}
+
+	return &UsersPage{
+		Users: v.Users,
+		Total: total,
+		More:  more,
+	}, nil
+}
+
+// KnownClient returns the User record stored by the UniFi Controller for the
+// client with the specified MAC address on a specified site name, whether or
+// not the client is currently connected.  If the UniFi Controller has never
+// recorded the client, ErrNotFound is returned.
+func (c *Client) KnownClient(siteName string, mac string) (*User, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := getList[User](context.Background(), c, siteName, "rest/user?mac="+hw.String())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if bytes.Equal(u.MAC, hw) {
+			return u, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// SetStationFixedIP reserves a fixed IP address on the network with the
+// specified ID for the client with the specified MAC address on a specified
+// site name, and returns the ID of the client's User record.
+//
+// The client need not be connected: if the UniFi Controller has never
+// recorded the client, a User record is created for it first.
+func (c *Client) SetStationFixedIP(siteName string, mac string, networkID string, ip net.IP) (string, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", err
+	}
+
+	if ip.To4() == nil {
+		return "", fmt.Errorf("invalid fixed IPv4 address: %v", ip)
+	}
+
+	var id string
+	u, err := c.KnownClient(siteName, hw.String())
+	switch err {
+	case nil:
+		id = u.ID
+	case ErrNotFound:
+		id, err = c.createUser(siteName, hw)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", err
+	}
+
+	req, err := c.newRequest(
+		http.MethodPut,
+		fmt.Sprintf("/api/s/%s/rest/user/%s", siteName, id),
+		&userFixedIP{
+			UseFixedIP: true,
+			NetworkID:  networkID,
+			FixedIP:    ip.String(),
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = c.do(req, nil)
+	return id, err
+}
+
+// A StationUpdate specifies changes to the User record of a client, for use
+// with Client.UpdateStation.  Only fields which are not nil are changed, so a
+// field which points to an empty value clears it on the UniFi Controller.
+type StationUpdate struct {
+	Name        *string
+	Note        *string
+	UserGroupID *string
+
+	// FixedIP, if set, reserves the specified IPv4 address for the client,
+	// or removes the client's reservation if it points to a nil address.
+	// NetworkID specifies the network on which an address is reserved.
+	FixedIP   *net.IP
+	NetworkID *string
+}
+
+// UpdateStation applies the changes in upd to the User record with the
+// specified ID on a specified site name, using a single request so that the
+// changes are applied together.  If upd contains no changes, no request is
+// made.
+func (c *Client) UpdateStation(siteName string, userID string, upd StationUpdate) error {
+	body := make(map[string]interface{})
+	if upd.Name != nil {
+		body["name"] = *upd.Name
+	}
+	if upd.Note != nil {
+		body["note"] = *upd.Note
+		body["noted"] = *upd.Note != ""
+	}
+	if upd.UserGroupID != nil {
+		body["usergroup_id"] = *upd.UserGroupID
+	}
+	if upd.FixedIP != nil {
+		ip := *upd.FixedIP
+		switch {
+		case ip == nil:
+			body["use_fixedip"] = false
+		case ip.To4() == nil:
+			return fmt.Errorf("invalid fixed IPv4 address: %v", ip)
+		default:
+			body["use_fixedip"] = true
+			body["fixed_ip"] = ip.String()
+		}
+	}
+	if upd.NetworkID != nil {
+		body["network_id"] = *upd.NetworkID
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	req, err := c.newRequest(
+		http.MethodPut,
+		fmt.Sprintf("/api/s/%s/rest/user/%s", siteName, userID),
+		body,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
+
+// ForgetStations instructs the UniFi Controller to forget the clients with
+// the specified MAC addresses on a specified site name, removing their User
+// records and history.
+//
+// MAC addresses are normalized before they are sent.  Invalid MAC addresses
+// do not prevent the remaining clients from being forgotten; the returned
+// error joins the errors for each MAC address which could not be forgotten.
+func (c *Client) ForgetStations(siteName string, macs []string) error {
+	var (
+		errs  []error
+		valid = make([]string, 0, len(macs))
+		seen  = make(map[string]struct{}, len(macs))
+	)
+
+	for _, m := range macs {
+		hw, err := net.ParseMAC(m)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to forget station %q: %w", m, err))
+			continue
+		}
+
+		mac := hw.String()
+		if _, ok := seen[mac]; ok {
+			continue
+		}
+		seen[mac] = struct{}{}
+
+		valid = append(valid, mac)
+	}
+
+	if len(valid) > 0 {
+		if err := c.stamgr(siteName, &stationCommand{
+			Command: "forget-sta",
+			MACs:    valid,
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to forget stations %v: %w", valid, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ForgetStaleStations instructs the UniFi Controller to forget the clients on
+// a specified site name which have not been seen for longer than olderThan.
+// Clients which do not report when they were last seen are never forgotten.
+// olderThan must be greater than zero.  See ForgetStations for details.
+func (c *Client) ForgetStaleStations(siteName string, olderThan time.Duration) error {
+	// Forgetting is irreversible, so never forget every client at once.
+	if olderThan <= 0 {
+		return fmt.Errorf("stale client age must be greater than zero: %v", olderThan)
+	}
+
+	page, err := c.AllUsers(siteName, nil)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+
+	var macs []string
+	for _, u := range page.Users {
+		if u.MAC == nil || u.LastSeen.Unix() <= 0 || !u.LastSeen.Before(cutoff) {
+			continue
+		}
+
+		macs = append(macs, u.MAC.String())
+	}
+
+	return c.ForgetStations(siteName, macs)
+}
+
+// BlockStation blocks the client with the specified MAC address from
+// connecting to any WLAN on a specified site name.  If the UniFi Controller
+// rejects the command, its error is returned.
+func (c *Client) BlockStation(siteName string, mac net.HardwareAddr) error {
+	return c.stationMACCommand(siteName, "block-sta", mac)
+}
+
+// UnblockStation permits the previously blocked client with the specified MAC
+// address to connect to WLANs on a specified site name.  If the UniFi
+// Controller rejects the command, its error is returned.
+func (c *Client) UnblockStation(siteName string, mac net.HardwareAddr) error {
+	return c.stationMACCommand(siteName, "unblock-sta", mac)
+}
+
+// ReconnectStation forces the client with the specified MAC address on a
+// specified site name to disconnect, so that it re-associates with the
+// network.  This can be used to move a roaming client onto a less congested
+// access point.  If the UniFi Controller rejects the command, its error is
+// returned.
+func (c *Client) ReconnectStation(siteName string, mac net.HardwareAddr) error {
+	return c.stationMACCommand(siteName, "kick-sta", mac)
+}
+
+// stationMACCommand issues a station manager command which applies to the
+// client with a single MAC address on a site.
+func (c *Client) stationMACCommand(siteName string, cmd string, mac net.HardwareAddr) error {
+	// The UniFi Controller identifies clients by their EUI-48 address in
+	// lowercase, colon-separated form, which is what String produces.
+	if err := checkMAC("station", cmd, mac); err != nil {
+		return err
+	}
+
+	return c.stamgr(siteName, &stationCommand{
+		Command: cmd,
+		MAC:     mac.String(),
+	})
+}
+
+// checkMAC verifies that mac is a valid MAC address for a command issued to
+// one of the UniFi Controller's managers.  kind describes what mac identifies,
+// such as "station" or "device", for use in errors.
+func checkMAC(kind string, cmd string, mac net.HardwareAddr) error {
+	if len(mac) != 6 {
+		return fmt.Errorf("invalid %s MAC address %q for %s", kind, mac, cmd)
+	}
+
+	return nil
+}
+
+// stamgr issues a command to the UniFi Controller's station manager.  cmd is
+// typically a *stationCommand, but commands with additional parameters use
+// their own types.
+func (c *Client) stamgr(siteName string, cmd interface{}) error {
+	req, err := c.newRequest(
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/stamgr", siteName),
+		cmd,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
+
+// A stationCommand is a command sent to the UniFi Controller's station
+// manager.
+type stationCommand struct {
+	Command string   `json:"cmd"`
+	MAC     string   `json:"mac,omitempty"`
+	MACs    []string `json:"macs,omitempty"`
+}
+
+// createUser creates a User record for the client with the specified MAC
+// address on a site, and returns its ID.
+func (c *Client) createUser(siteName string, mac net.HardwareAddr) (string, error) {
+	var v struct {
+		Users []*User `json:"data"`
+	}
+
+	req, err := c.newRequest(
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/rest/user", siteName),
+		&struct {
+			MAC string `json:"mac"`
+		}{
+			MAC: mac.String(),
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := c.do(req, &v); err != nil {
+		return "", err
+	}
+
+	if len(v.Users) == 0 {
+		return "", fmt.Errorf("no user record created for client %s", mac)
+	}
+
+	return v.Users[0].ID, nil
+}
+
+// A userFixedIP is the raw structure used to set a User's fixed IP address.
+type userFixedIP struct {
+	UseFixedIP bool   `json:"use_fixedip"`
+	NetworkID  string `json:"network_id"`
+	FixedIP    string `json:"fixed_ip"`
+}
+
+// A userQuery is the raw structure of a query used to filter Users.
+type userQuery struct {
+	Type   string `json:"type"`
+	Conn   string `json:"conn"`
+	Within int    `json:"within,omitempty"`
+	Start  int    `json:"_start,omitempty"`
+	Limit  int    `json:"_limit,omitempty"`
+	Sort   string `json:"_sort,omitempty"`
+}
+
+// A User is a client which is known to a UniFi Controller, whether or not it
+// is currently connected.
+type User struct {
+	ID          string
+	FirstSeen   time.Time
+	FixedIP     net.IP
+	Hostname    string // Device-provided name
+	IsGuest     bool
+	IsWired     bool
+	LastSeen    time.Time
+	MAC         net.HardwareAddr
+	Name        string // Unifi-set name
+	NetworkID   string
+	Note        string
+	OUI         string
+	SiteID      string
+	UseFixedIP  bool
+	UserGroupID string
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a User is unmarshaled.
+func (*User) raw() interface{} { return new(user) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of a User.
+func (u *User) UnmarshalJSON(b []byte) error {
+	var us user
+	if err := json.Unmarshal(b, &us); err != nil {
+		return err
+	}
+
+	mac, err := net.ParseMAC(us.MAC)
+	if err != nil {
+		return err
+	}
+
+	var fixedIP net.IP
+	if us.UseFixedIP {
+		fixedIP = net.ParseIP(us.FixedIP)
+	}
+
+	*u = User{
+		ID:          us.ID,
+		FirstSeen:   time.Unix(us.FirstSeen, 0),
+		FixedIP:     fixedIP,
+		Hostname:    us.Hostname,
+		IsGuest:     us.IsGuest,
+		IsWired:     us.IsWired,
+		LastSeen:    time.Unix(us.LastSeen, 0),
+		MAC:         mac,
+		Name:        us.Name,
+		NetworkID:   us.NetworkID,
+		Note:        us.Note,
+		OUI:         us.OUI,
+		SiteID:      us.SiteID,
+		UseFixedIP:  us.UseFixedIP,
+		UserGroupID: us.UsergroupID,
+	}
+
+	return nil
+}
+
+// A user is the raw structure of a User returned from the UniFi Controller
+// API.
+type user struct {
+	ID          string `json:"_id"`
+	FirstSeen   int64  `json:"first_seen"`
+	FixedIP     string `json:"fixed_ip"`
+	Hostname    string `json:"hostname"`
+	IsGuest     bool   `json:"is_guest"`
+	IsWired     bool   `json:"is_wired"`
+	LastSeen    int64  `json:"last_seen"`
+	MAC         string `json:"mac"`
+	Name        string `json:"name"`
+	NetworkID   string `json:"network_id"`
+	Noted       bool   `json:"noted"`
+	Note        string `json:"note"`
+	OUI         string `json:"oui"`
+	SiteID      string `json:"site_id"`
+	UseFixedIP  bool   `json:"use_fixedip"`
+	UsergroupID string `json:"usergroup_id"`
+}
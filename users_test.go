@@ -0,0 +1,590 @@
+package unifi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientAllUsers(t *testing.T) {
+	const wantSite = "default"
+
+	macs := []net.HardwareAddr{
+		{0xde, 0xad, 0xbe, 0xef, 0xde, 0x01},
+		{0xde, 0xad, 0xbe, 0xef, 0xde, 0x02},
+	}
+
+	users := make([]user, 0, len(macs))
+	for _, mac := range macs {
+		users = append(users, user{MAC: mac.String()})
+	}
+
+	var tests = []struct {
+		desc  string
+		opts  *AllUsersOptions
+		q     *userQuery
+		total int
+		more  bool
+	}{
+		{
+			desc: "no options",
+			q: &userQuery{
+				Type: "all",
+				Conn: "all",
+			},
+		},
+		{
+			desc: "full page",
+			opts: &AllUsersOptions{
+				Within: 90 * time.Minute,
+				Start:  2,
+				Limit:  2,
+				Sort:   SortDescending,
+			},
+			q: &userQuery{
+				Type:   "all",
+				Conn:   "all",
+				Within: 2,
+				Start:  2,
+				Limit:  2,
+				Sort:   "-last_seen",
+			},
+			more: true,
+		},
+		{
+			desc: "last page",
+			opts: &AllUsersOptions{
+				Limit: 3,
+				Sort:  SortAscending,
+			},
+			q: &userQuery{
+				Type:  "all",
+				Conn:  "all",
+				Limit: 3,
+				Sort:  "last_seen",
+			},
+		},
+		{
+			desc: "reported total remaining",
+			opts: &AllUsersOptions{
+				Limit: 2,
+			},
+			q: &userQuery{
+				Type:  "all",
+				Conn:  "all",
+				Limit: 2,
+			},
+			total: 5,
+			more:  true,
+		},
+		{
+			desc: "reported total exhausted",
+			opts: &AllUsersOptions{
+				Start: 3,
+				Limit: 2,
+			},
+			q: &userQuery{
+				Type:  "all",
+				Conn:  "all",
+				Start: 3,
+				Limit: 2,
+			},
+			total: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := struct {
+				Meta  pageMeta `json:"meta"`
+				Users []user   `json:"data"`
+			}{
+				Meta:  pageMeta{Count: tt.total},
+				Users: users,
+			}
+
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodPost,
+				fmt.Sprintf("/api/s/%s/stat/alluser", wantSite),
+				tt.q,
+				v,
+			))
+			defer done()
+
+			page, err := c.AllUsers(wantSite, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.AllUsers: %v", err)
+			}
+
+			if want, got := len(macs), len(page.Users); want != got {
+				t.Fatalf("unexpected number of Users:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+
+			if want, got := tt.total, page.Total; want != got {
+				t.Fatalf("unexpected total number of Users:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+
+			if want, got := tt.more, page.More; want != got {
+				t.Fatalf("unexpected more pages value:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientKnownClient(t *testing.T) {
+	const wantSite = "default"
+	wantMAC := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad}
+
+	v := struct {
+		Users []user `json:"data"`
+	}{
+		Users: []user{{
+			MAC:  wantMAC.String(),
+			Name: "somename",
+			Note: "somenote",
+		}},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/rest/user", wantSite),
+		nil,
+		v,
+	))
+	defer done()
+
+	u, err := c.KnownClient(wantSite, wantMAC.String())
+	if err != nil {
+		t.Fatalf("unexpected error from Client.KnownClient: %v", err)
+	}
+
+	if want, got := "somenote", u.Note; want != got {
+		t.Fatalf("unexpected note:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	if _, err := c.KnownClient(wantSite, "ff:ff:ff:ff:ff:ff"); err != ErrNotFound {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", ErrNotFound, err)
+	}
+}
+
+func TestClientSetStationFixedIP(t *testing.T) {
+	const (
+		wantSite      = "default"
+		wantID        = "abcdef1234567890"
+		wantNetworkID = "somenetwork"
+	)
+	var (
+		wantMAC = net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad}
+		wantIP  = net.IPv4(192, 168, 1, 2)
+	)
+
+	var tests = []struct {
+		desc  string
+		known bool
+	}{
+		{desc: "known client", known: true},
+		{desc: "unknown client", known: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var created, updated bool
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					var users []user
+					if tt.known {
+						users = append(users, user{ID: wantID, MAC: wantMAC.String()})
+					}
+
+					testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/rest/user", wantSite), nil,
+						struct {
+							Users []user `json:"data"`
+						}{Users: users},
+					)(w, r)
+				case http.MethodPost:
+					created = true
+					testHandler(t, http.MethodPost, fmt.Sprintf("/api/s/%s/rest/user", wantSite),
+						map[string]string{"mac": wantMAC.String()},
+						struct {
+							Users []user `json:"data"`
+						}{Users: []user{{ID: wantID, MAC: wantMAC.String()}}},
+					)(w, r)
+				case http.MethodPut:
+					updated = true
+					testHandler(t, http.MethodPut, fmt.Sprintf("/api/s/%s/rest/user/%s", wantSite, wantID),
+						&userFixedIP{
+							UseFixedIP: true,
+							NetworkID:  wantNetworkID,
+							FixedIP:    wantIP.String(),
+						},
+						nil,
+					)(w, r)
+				}
+			})
+			defer done()
+
+			id, err := c.SetStationFixedIP(wantSite, wantMAC.String(), wantNetworkID, wantIP)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.SetStationFixedIP: %v", err)
+			}
+
+			if want, got := wantID, id; want != got {
+				t.Fatalf("unexpected user ID:\n- want: %v\n-  got: %v", want, got)
+			}
+
+			if want, got := !tt.known, created; want != got {
+				t.Fatalf("unexpected user creation:\n- want: %v\n-  got: %v", want, got)
+			}
+
+			if !updated {
+				t.Fatal("user fixed IP was not updated")
+			}
+		})
+	}
+}
+
+func TestClientUpdateStation(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef1234567890"
+	)
+
+	str := func(s string) *string { return &s }
+	ip := func(ip net.IP) *net.IP { return &ip }
+
+	var tests = []struct {
+		desc string
+		upd  StationUpdate
+		body map[string]interface{}
+		err  error
+	}{
+		{
+			desc: "no changes",
+		},
+		{
+			desc: "all fields",
+			upd: StationUpdate{
+				Name:        str("printer"),
+				Note:        str("2nd floor"),
+				UserGroupID: str("somegroup"),
+				FixedIP:     ip(net.IPv4(192, 168, 1, 2)),
+				NetworkID:   str("somenetwork"),
+			},
+			body: map[string]interface{}{
+				"name":         "printer",
+				"note":         "2nd floor",
+				"noted":        true,
+				"usergroup_id": "somegroup",
+				"use_fixedip":  true,
+				"fixed_ip":     "192.168.1.2",
+				"network_id":   "somenetwork",
+			},
+		},
+		{
+			desc: "clear",
+			upd: StationUpdate{
+				Name:    str(""),
+				Note:    str(""),
+				FixedIP: ip(nil),
+			},
+			body: map[string]interface{}{
+				"name":        "",
+				"note":        "",
+				"noted":       false,
+				"use_fixedip": false,
+			},
+		},
+		{
+			desc: "invalid fixed IP",
+			upd: StationUpdate{
+				FixedIP: ip(net.ParseIP("2001:db8::1")),
+			},
+			err: errors.New("invalid fixed IPv4 address"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var updated bool
+			c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				updated = true
+				testHandler(t, http.MethodPut, fmt.Sprintf("/api/s/%s/rest/user/%s", wantSite, wantID),
+					tt.body, nil)(w, r)
+			})
+			defer done()
+
+			err := c.UpdateStation(wantSite, wantID, tt.upd)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err == nil && err != nil {
+				t.Fatalf("unexpected error from Client.UpdateStation: %v", err)
+			}
+
+			if want, got := tt.body != nil, updated; want != got {
+				t.Fatalf("unexpected User update:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestUserUnmarshalJSON(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    []byte
+		u    *User
+		err  error
+	}{
+		{
+			desc: "invalid JSON",
+			b:    []byte(`<>`),
+			err:  errors.New("invalid character"),
+		},
+		{
+			desc: "invalid MAC",
+			b:    []byte(`{"mac":"foo"}`),
+			err:  errors.New("invalid MAC address"),
+		},
+		{
+			desc: "fixed IP disabled",
+			b:    []byte(`{"mac":"de:ad:be:ef:de:ad","use_fixedip":false,"fixed_ip":"192.168.1.2"}`),
+			u: &User{
+				FirstSeen: time.Unix(0, 0),
+				LastSeen:  time.Unix(0, 0),
+				MAC:       net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+			},
+		},
+		{
+			desc: "OK",
+			b: []byte(`{
+	"_id": "abcdef1234567890",
+	"first_seen": 1,
+	"hostname": "somehost",
+	"is_guest": true,
+	"last_seen": 2,
+	"mac": "de:ad:be:ef:de:ad",
+	"name": "somename",
+	"oui": "Ubiquiti",
+	"site_id": "somesite",
+	"note": "somenote",
+	"usergroup_id": "somegroup",
+	"use_fixedip": true,
+	"fixed_ip": "192.168.1.2",
+	"network_id": "somenetwork"
+}`),
+			u: &User{
+				ID:          "abcdef1234567890",
+				FirstSeen:   time.Unix(1, 0),
+				Hostname:    "somehost",
+				IsGuest:     true,
+				LastSeen:    time.Unix(2, 0),
+				MAC:         net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad},
+				Name:        "somename",
+				OUI:         "Ubiquiti",
+				SiteID:      "somesite",
+				Note:        "somenote",
+				UserGroupID: "somegroup",
+				UseFixedIP:  true,
+				FixedIP:     net.IPv4(192, 168, 1, 2),
+				NetworkID:   "somenetwork",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			u := new(User)
+			err := u.UnmarshalJSON(tt.b)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err != nil {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.u, u; !reflect.DeepEqual(got, want) {
+				t.Fatalf("unexpected User:\n- want: %+v\n-  got: %+v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientForgetStations(t *testing.T) {
+	const wantSite = "default"
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/cmd/stamgr", wantSite),
+		&stationCommand{
+			Command: "forget-sta",
+			MACs:    []string{"de:ad:be:ef:de:ad", "de:ad:be:ef:de:ae"},
+		},
+		nil,
+	))
+	defer done()
+
+	err := c.ForgetStations(wantSite, []string{
+		"DE:AD:BE:EF:DE:AD",
+		"foo",
+		"de-ad-be-ef-de-ae",
+		"de:ad:be:ef:de:ad",
+	})
+	if want, got := `failed to forget station "foo"`, errStr(err); !strings.Contains(got, want) {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientForgetStaleStations(t *testing.T) {
+	const wantSite = "default"
+
+	now := time.Now()
+
+	var forgot bool
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/s/%s/stat/alluser", wantSite):
+			testHandler(t, http.MethodPost, r.URL.Path,
+				&userQuery{Type: "all", Conn: "all"},
+				struct {
+					Users []user `json:"data"`
+				}{
+					Users: []user{
+						{MAC: "de:ad:be:ef:de:01", LastSeen: now.Unix()},
+						{MAC: "de:ad:be:ef:de:02", LastSeen: now.Add(-48 * time.Hour).Unix()},
+						{MAC: "de:ad:be:ef:de:03", LastSeen: now.Add(-2 * time.Hour).Unix()},
+						// Never seen, so it must not be forgotten.
+						{MAC: "de:ad:be:ef:de:04"},
+					},
+				},
+			)(w, r)
+		case fmt.Sprintf("/api/s/%s/cmd/stamgr", wantSite):
+			forgot = true
+			testHandler(t, http.MethodPost, r.URL.Path,
+				&stationCommand{
+					Command: "forget-sta",
+					MACs:    []string{"de:ad:be:ef:de:02"},
+				},
+				nil,
+			)(w, r)
+		default:
+			t.Fatalf("unexpected URL path: %v", r.URL.Path)
+		}
+	})
+	defer done()
+
+	if err := c.ForgetStaleStations(wantSite, 24*time.Hour); err != nil {
+		t.Fatalf("unexpected error from Client.ForgetStaleStations: %v", err)
+	}
+
+	if !forgot {
+		t.Fatal("expected stale stations to be forgotten")
+	}
+}
+
+func TestClientForgetStaleStationsBadAge(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request: %v", r.URL.Path)
+	})
+	defer done()
+
+	for _, d := range []time.Duration{0, -time.Hour} {
+		err := c.ForgetStaleStations("default", d)
+		if want, got := "must be greater than zero", errStr(err); !strings.Contains(got, want) {
+			t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", want, got)
+		}
+	}
+}
+
+func TestClientStationMACCommands(t *testing.T) {
+	const wantSite = "default"
+
+	mac := net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad}
+
+	var tests = []struct {
+		desc string
+		cmd  string
+		fn   func(c *Client, mac net.HardwareAddr) error
+	}{
+		{
+			desc: "block",
+			cmd:  "block-sta",
+			fn: func(c *Client, mac net.HardwareAddr) error {
+				return c.BlockStation(wantSite, mac)
+			},
+		},
+		{
+			desc: "unblock",
+			cmd:  "unblock-sta",
+			fn: func(c *Client, mac net.HardwareAddr) error {
+				return c.UnblockStation(wantSite, mac)
+			},
+		},
+		{
+			desc: "reconnect",
+			cmd:  "kick-sta",
+			fn: func(c *Client, mac net.HardwareAddr) error {
+				return c.ReconnectStation(wantSite, mac)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodPost,
+				fmt.Sprintf("/api/s/%s/cmd/stamgr", wantSite),
+				map[string]string{
+					"cmd": tt.cmd,
+					"mac": "de:ad:be:ef:de:ad",
+				},
+				nil,
+			))
+			defer done()
+
+			if err := tt.fn(c, mac); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := tt.fn(c, net.HardwareAddr{0xde, 0xad}); err == nil {
+				t.Fatal("expected an error for an invalid MAC address")
+			}
+		})
+	}
+}
+
+func TestClientStationMACCommandRejected(t *testing.T) {
+	const wantMsg = "api.err.UnknownStation"
+
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		_, _ = w.Write([]byte(`{"meta":{"rc":"error","msg":"` + wantMsg + `"},"data":[]}`))
+	})
+	defer done()
+
+	err := c.ReconnectStation("default", net.HardwareAddr{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad})
+
+	var aerr *APIError
+	if !errors.As(err, &aerr) || aerr.Message != wantMsg {
+		t.Fatalf("unexpected error:\n- want: %v\n-  got: %v", wantMsg, err)
+	}
+}
@@ -0,0 +1,159 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// Vouchers returns all of the guest portal Vouchers for a specified site name.
+func (c *Client) Vouchers(siteName string) ([]*Voucher, error) {
+	return getList[Voucher](context.Background(), c, siteName, "stat/voucher")
+}
+
+// A VoucherGuest is a Guest authorized by redeeming a Voucher.
+type VoucherGuest struct {
+	Guest *Guest
+
+	// RemainingMB is the amount of data in megabytes which the Guest may
+	// still transfer under the Voucher's data usage limit, or -1 if the
+	// Voucher has no limit.
+	RemainingMB int
+}
+
+// VoucherGuests returns the Guests on a specified site name which were
+// authorized by redeeming Voucher v, along with the data remaining to each.
+// The Voucher's data usage limit applies to each Guest separately, so a
+// Voucher which may be redeemed more than once has no single remaining
+// quota.
+//
+// The UniFi Controller only reports Guests authorized within a time window,
+// so the window is extended back to the creation of v to include every
+// redemption.
+func (c *Client) VoucherGuests(siteName string, v *Voucher) ([]*VoucherGuest, error) {
+	q := &guestQuery{Within: 1}
+	if h := int(math.Ceil(time.Since(v.Created).Hours())); h > q.Within {
+		q.Within = h
+	}
+
+	guests, err := queryList[Guest](context.Background(), c, siteName, "stat/guest", q)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*VoucherGuest, 0)
+	for _, g := range guests {
+		if g.VoucherID != v.ID {
+			continue
+		}
+
+		out = append(out, &VoucherGuest{
+			Guest:       g,
+			RemainingMB: remainingMB(v.QuotaMB, g.ReceiveBytes+g.TransmitBytes),
+		})
+	}
+
+	return out, nil
+}
+
+// remainingMB returns the amount of data in megabytes which remains of a data
+// usage limit of quotaMB after used bytes have been transferred, or -1 if
+// quotaMB is zero, indicating no limit.
+func remainingMB(quotaMB int, used int64) int {
+	if quotaMB == 0 {
+		return -1
+	}
+
+	// The UniFi Controller's megabytes are 2^20 bytes.
+	if n := quotaMB - int(used>>20); n > 0 {
+		return n
+	}
+
+	return 0
+}
+
+// A guestQuery is the raw structure of a query for Guests, which selects those
+// authorized within the specified number of hours.
+type guestQuery struct {
+	Within int `json:"within"`
+}
+
+// A Voucher is a code which can be redeemed on a guest portal to grant
+// network access.
+type Voucher struct {
+	ID       string
+	Code     string
+	Created  time.Time
+	Duration time.Duration
+	Note     string
+	SiteID   string
+	Status   string
+
+	// Quota is the number of times the Voucher may be redeemed.  A Quota of
+	// zero indicates the Voucher may be redeemed an unlimited number of times.
+	Quota int
+
+	// Used is the number of times the Voucher has been redeemed.
+	Used int
+
+	// Redeemed reports whether the Voucher has been redeemed at least once.
+	Redeemed bool
+
+	// QuotaMB is the data usage limit in megabytes applied to each guest who
+	// redeems the Voucher.  A QuotaMB of zero indicates no limit.  Use
+	// Client.VoucherGuests to determine the data remaining to each guest.
+	QuotaMB int
+}
+
+// Unlimited reports whether the Voucher may be redeemed an unlimited number
+// of times.
+func (v *Voucher) Unlimited() bool {
+	return v.Quota == 0
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a Voucher is unmarshaled.
+func (*Voucher) raw() interface{} { return new(voucher) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of a Voucher.
+func (v *Voucher) UnmarshalJSON(b []byte) error {
+	var vo voucher
+	if err := json.Unmarshal(b, &vo); err != nil {
+		return err
+	}
+
+	*v = Voucher{
+		ID:       vo.ID,
+		Code:     vo.Code,
+		Created:  time.Unix(vo.CreateTime, 0),
+		Duration: time.Duration(vo.Duration) * time.Minute,
+		Note:     vo.Note,
+		SiteID:   vo.SiteID,
+		Status:   vo.Status,
+		Quota:    vo.Quota,
+		Used:     vo.Used,
+		Redeemed: vo.Used > 0,
+		QuotaMB:  vo.QosUsageQuota,
+	}
+
+	return nil
+}
+
+// A voucher is the raw structure of a Voucher returned from the UniFi
+// Controller API.
+type voucher struct {
+	ID            string `json:"_id"`
+	AdminName     string `json:"admin_name"`
+	Code          string `json:"code"`
+	CreateTime    int64  `json:"create_time"`
+	Duration      int    `json:"duration"`
+	ForHotspot    bool   `json:"for_hotspot"`
+	Note          string `json:"note"`
+	QosOverwrite  bool   `json:"qos_overwrite"`
+	QosUsageQuota int    `json:"qos_usage_quota"`
+	Quota         int    `json:"quota"`
+	SiteID        string `json:"site_id"`
+	Status        string `json:"status"`
+	Used          int    `json:"used"`
+}
@@ -0,0 +1,209 @@
+package unifi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientVouchers(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef123457890"
+		wantCode = "1234567890"
+	)
+
+	wantVoucher := &Voucher{
+		ID:       wantID,
+		Code:     wantCode,
+		Created:  time.Unix(0, 0),
+		Duration: 24 * time.Hour,
+		Quota:    1,
+		QuotaMB:  100,
+	}
+
+	v := struct {
+		Vouchers []voucher `json:"data"`
+	}{
+		Vouchers: []voucher{{
+			ID:            wantID,
+			Code:          wantCode,
+			Duration:      1440,
+			Quota:         1,
+			QosUsageQuota: 100,
+		}},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/stat/voucher", wantSite),
+		nil,
+		v,
+	))
+	defer done()
+
+	vouchers, err := c.Vouchers(wantSite)
+	if err != nil {
+		t.Fatalf("unexpected error from Client.Vouchers: %v", err)
+	}
+
+	if want, got := 1, len(vouchers); want != got {
+		t.Fatalf("unexpected number of Vouchers:\n- want: %d\n-  got: %d",
+			want, got)
+	}
+
+	if want, got := wantVoucher, vouchers[0]; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected Voucher:\n- want: %#v\n-  got: %#v",
+			want, got)
+	}
+}
+
+func TestVoucherUnmarshalJSON(t *testing.T) {
+	var tests = []struct {
+		desc string
+		b    []byte
+		v    *Voucher
+		err  error
+	}{
+		{
+			desc: "invalid JSON",
+			b:    []byte(`<>`),
+			err:  errors.New("invalid character"),
+		},
+		{
+			desc: "unused single use",
+			b:    []byte(`{"code":"1","quota":1,"used":0,"qos_usage_quota":100,"status":"VALID_ONE"}`),
+			v: &Voucher{
+				Code:    "1",
+				Created: time.Unix(0, 0),
+				Status:  "VALID_ONE",
+				Quota:   1,
+				QuotaMB: 100,
+			},
+		},
+		{
+			desc: "redeemed single use",
+			b:    []byte(`{"code":"2","quota":1,"used":1,"status":"USED"}`),
+			v: &Voucher{
+				Code:     "2",
+				Created:  time.Unix(0, 0),
+				Status:   "USED",
+				Quota:    1,
+				Used:     1,
+				Redeemed: true,
+			},
+		},
+		{
+			desc: "unlimited",
+			b:    []byte(`{"code":"3","quota":0,"used":5,"status":"VALID_MULTI"}`),
+			v: &Voucher{
+				Code:     "3",
+				Created:  time.Unix(0, 0),
+				Status:   "VALID_MULTI",
+				Used:     5,
+				Redeemed: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := new(Voucher)
+			err := v.UnmarshalJSON(tt.b)
+			if want, got := errStr(tt.err), errStr(err); !strings.Contains(got, want) {
+				t.Fatalf("unexpected error:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+			if tt.err != nil {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.v, v; !reflect.DeepEqual(got, want) {
+				t.Fatalf("unexpected Voucher:\n- want: %+v\n-  got: %+v",
+					want, got)
+			}
+		})
+	}
+}
+
+func TestClientVoucherGuests(t *testing.T) {
+	const (
+		wantSite = "default"
+		wantID   = "abcdef123457890"
+	)
+
+	g := struct {
+		Guests []guest `json:"data"`
+	}{
+		Guests: []guest{
+			{MAC: "de:ad:be:ef:00:01", VoucherID: wantID, RxBytes: 3 << 20, TxBytes: 1<<20 + 1},
+			{MAC: "de:ad:be:ef:00:02", VoucherID: wantID, RxBytes: 200 << 20},
+			{MAC: "de:ad:be:ef:00:03", VoucherID: "other", RxBytes: 1 << 30},
+		},
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodPost,
+		fmt.Sprintf("/api/s/%s/stat/guest", wantSite),
+		// The window must reach back to the Voucher's creation.
+		&guestQuery{Within: 49},
+		g,
+	))
+	defer done()
+
+	var tests = []struct {
+		desc    string
+		quotaMB int
+		mb      []int
+	}{
+		{
+			desc: "unlimited",
+			mb:   []int{-1, -1},
+		},
+		{
+			// Each guest is limited separately, so one exhausting the quota
+			// does not affect the other.
+			desc:    "limited",
+			quotaMB: 100,
+			mb:      []int{96, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := &Voucher{
+				ID:      wantID,
+				Created: time.Now().Add(-48*time.Hour - time.Minute),
+				QuotaMB: tt.quotaMB,
+			}
+
+			guests, err := c.VoucherGuests(wantSite, v)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.VoucherGuests: %v", err)
+			}
+
+			var macs []string
+			var mb []int
+			for _, vg := range guests {
+				macs = append(macs, vg.Guest.MAC.String())
+				mb = append(mb, vg.RemainingMB)
+			}
+
+			if want, got := []string{"de:ad:be:ef:00:01", "de:ad:be:ef:00:02"}, macs; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected Guests:\n- want: %v\n-  got: %v", want, got)
+			}
+			if want, got := tt.mb, mb; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected remaining data:\n- want: %v\n-  got: %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// A WLAN is a wireless network broadcast by the access points of a site.
+type WLAN struct {
+	ID       string `json:"_id"`
+	Enabled  bool   `json:"enabled"`
+	IsGuest  bool   `json:"is_guest"`
+	Name     string `json:"name"`
+	Security string `json:"security"`
+	SiteID   string `json:"site_id"`
+
+	// WPAMode, Encryption, and PMF describe the WPA configuration of the
+	// WLAN, such as "wpa2", "ccmp", and "optional".  Open reports whether
+	// the WLAN requires no authentication at all.
+	//
+	// The UniFi Controller retains WPA settings for open WLANs even though
+	// they are not used, so for open WLANs WPAMode and Encryption are always
+	// empty and PMF is always "disabled".
+	WPAMode    string `json:"wpa_mode,omitempty"`
+	Encryption string `json:"wpa_enc,omitempty"`
+	PMF        string `json:"pmf_mode,omitempty"`
+	Open       bool   `json:"-"`
+}
+
+// raw implements rawUnmarshaler, returning the raw structure from which
+// a WLAN is unmarshaled.
+func (*WLAN) raw() interface{} { return new(wlan) }
+
+// UnmarshalJSON unmarshals the raw JSON representation of a WLAN.
+func (w *WLAN) UnmarshalJSON(b []byte) error {
+	var wl wlan
+	if err := json.Unmarshal(b, &wl); err != nil {
+		return err
+	}
+
+	*w = WLAN(wl)
+	w.Open = w.Security == "open"
+	if w.Open {
+		w.WPAMode = ""
+		w.Encryption = ""
+		w.PMF = "disabled"
+	}
+
+	return nil
+}
+
+// A wlan is the raw structure of a WLAN returned from the UniFi Controller
+// API.  It shares the fields of WLAN, but not its UnmarshalJSON method.
+type wlan WLAN
+
+// WLANs returns all of the WLANs for a specified site name.
+func (c *Client) WLANs(siteName string) ([]*WLAN, error) {
+	return getList[WLAN](context.Background(), c, siteName, "rest/wlanconf")
+}
+
+// SetWLANEnabled enables or disables the WLAN with the specified ID on a
+// specified site name.
+func (c *Client) SetWLANEnabled(siteName string, id string, enabled bool) error {
+	req, err := c.newRequest(
+		http.MethodPut,
+		fmt.Sprintf("/api/s/%s/rest/wlanconf/%s", siteName, id),
+		&struct {
+			Enabled bool `json:"enabled"`
+		}{
+			Enabled: enabled,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
+
+// SetAllGuestWLANsEnabled enables or disables all of the guest WLANs on a
+// specified site name.  WLANs which are not guest WLANs are never modified,
+// and guest WLANs which are already in the desired state are skipped.
+//
+// Every guest WLAN is updated even if updating another fails; the returned
+// error joins the errors for each WLAN which could not be updated.
+func (c *Client) SetAllGuestWLANsEnabled(siteName string, enabled bool) error {
+	wlans, err := c.WLANs(siteName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, w := range wlans {
+		if !w.IsGuest || w.Enabled == enabled {
+			continue
+		}
+
+		if err := c.SetWLANEnabled(siteName, w.ID, enabled); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update WLAN %q: %w", w.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// UnexpectedSSIDs returns a map of access point MAC addresses to the SSIDs
+// broadcast by each access point on a specified site name which do not
+// appear in approved.  SSIDs are compared case-sensitively, and each SSID is
+// reported once per access point, in sorted order.
+//
+// Hidden SSIDs, which access points report with an empty SSID, are ignored.
+// Access points which broadcast only approved SSIDs are omitted.
+func (c *Client) UnexpectedSSIDs(siteName string, approved []string) (map[string][]string, error) {
+	devices, err := c.Devices(siteName)
+	if err != nil {
+		return nil, err
+	}
+
+	ok := make(map[string]struct{}, len(approved))
+	for _, ssid := range approved {
+		ok[ssid] = struct{}{}
+	}
+
+	unexpected := make(map[string][]string)
+	for _, d := range devices {
+		if d.MAC == nil {
+			continue
+		}
+
+		seen := make(map[string]struct{})
+		for _, v := range d.VAPs {
+			if v.ESSID == "" {
+				continue
+			}
+			if _, found := ok[v.ESSID]; found {
+				continue
+			}
+			if _, found := seen[v.ESSID]; found {
+				continue
+			}
+			seen[v.ESSID] = struct{}{}
+
+			mac := d.MAC.String()
+			unexpected[mac] = append(unexpected[mac], v.ESSID)
+		}
+	}
+
+	for _, ssids := range unexpected {
+		sort.Strings(ssids)
+	}
+
+	return unexpected, nil
+}
@@ -0,0 +1,205 @@
+package unifi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestClientWLANs(t *testing.T) {
+	const wantSite = "default"
+
+	var tests = []struct {
+		desc string
+		raw  map[string]interface{}
+		wlan *WLAN
+	}{
+		{
+			desc: "open",
+			raw: map[string]interface{}{
+				"_id":      "abcdef1234567890",
+				"enabled":  true,
+				"is_guest": true,
+				"name":     "guest",
+				"security": "open",
+				// Retained by the controller, but unused.
+				"wpa_mode": "wpa2",
+				"wpa_enc":  "ccmp",
+			},
+			wlan: &WLAN{
+				ID:       "abcdef1234567890",
+				Enabled:  true,
+				IsGuest:  true,
+				Name:     "guest",
+				Security: "open",
+				PMF:      "disabled",
+				Open:     true,
+			},
+		},
+		{
+			desc: "WPA2 personal",
+			raw: map[string]interface{}{
+				"_id":      "abcdef1234567891",
+				"enabled":  true,
+				"name":     "home",
+				"security": "wpapsk",
+				"wpa_mode": "wpa2",
+				"wpa_enc":  "ccmp",
+				"pmf_mode": "optional",
+			},
+			wlan: &WLAN{
+				ID:         "abcdef1234567891",
+				Enabled:    true,
+				Name:       "home",
+				Security:   "wpapsk",
+				WPAMode:    "wpa2",
+				Encryption: "ccmp",
+				PMF:        "optional",
+			},
+		},
+		{
+			desc: "WPA enterprise without PMF",
+			raw: map[string]interface{}{
+				"_id":      "abcdef1234567892",
+				"name":     "corp",
+				"security": "wpaeap",
+				"wpa_mode": "auto",
+				"wpa_enc":  "tkip",
+			},
+			wlan: &WLAN{
+				ID:         "abcdef1234567892",
+				Name:       "corp",
+				Security:   "wpaeap",
+				WPAMode:    "auto",
+				Encryption: "tkip",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, done := testClient(t, testHandler(
+				t,
+				http.MethodGet,
+				fmt.Sprintf("/api/s/%s/rest/wlanconf", wantSite),
+				nil,
+				map[string]interface{}{
+					"data": []map[string]interface{}{tt.raw},
+				},
+			))
+			defer done()
+
+			wlans, err := c.WLANs(wantSite)
+			if err != nil {
+				t.Fatalf("unexpected error from Client.WLANs: %v", err)
+			}
+
+			if want, got := 1, len(wlans); want != got {
+				t.Fatalf("unexpected number of WLANs:\n- want: %d\n-  got: %d",
+					want, got)
+			}
+
+			if want, got := tt.wlan, wlans[0]; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected WLAN:\n- want: %+v\n-  got: %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestClientSetAllGuestWLANsEnabled(t *testing.T) {
+	const wantSite = "default"
+
+	wlans := []*WLAN{
+		{ID: "corp", Enabled: true, Name: "corp"},
+		{ID: "guest1", Enabled: true, IsGuest: true, Name: "guest1"},
+		{ID: "guest2", Enabled: true, IsGuest: true, Name: "guest2"},
+		{ID: "guest3", Enabled: false, IsGuest: true, Name: "guest3"},
+		{ID: "broken", Enabled: true, IsGuest: true, Name: "broken"},
+	}
+
+	var updated []string
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			testHandler(t, http.MethodGet, fmt.Sprintf("/api/s/%s/rest/wlanconf", wantSite), nil,
+				struct {
+					WLANs []*WLAN `json:"data"`
+				}{WLANs: wlans},
+			)(w, r)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/s/%s/rest/wlanconf/", wantSite))
+		if id == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		updated = append(updated, id)
+		testHandler(t, http.MethodPut, r.URL.Path, map[string]bool{"enabled": false}, nil)(w, r)
+	})
+	defer done()
+
+	err := c.SetAllGuestWLANsEnabled(wantSite, false)
+	if err == nil || !strings.Contains(err.Error(), `failed to update WLAN "broken"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(updated)
+	if want, got := []string{"guest1", "guest2"}, updated; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected updated WLANs:\n- want: %v\n-  got: %v", want, got)
+	}
+}
+
+func TestClientUnexpectedSSIDs(t *testing.T) {
+	const wantSite = "default"
+
+	vap := func(essid string) map[string]interface{} {
+		return map[string]interface{}{"essid": essid, "radio": "ng"}
+	}
+
+	c, done := testClient(t, testHandler(
+		t,
+		http.MethodGet,
+		fmt.Sprintf("/api/s/%s/stat/device", wantSite),
+		nil,
+		map[string]interface{}{
+			"data": []map[string]interface{}{
+				{
+					"inform_ip": "192.168.1.1",
+					"mac":       "de:ad:be:ef:00:01",
+					"vap_table": []map[string]interface{}{
+						vap("home"), vap("guest"),
+					},
+				},
+				{
+					"inform_ip": "192.168.1.1",
+					"mac":       "de:ad:be:ef:00:02",
+					"vap_table": []map[string]interface{}{
+						vap("home"), vap("Home"), vap(""), vap("free wifi"), vap("Home"),
+					},
+				},
+				{
+					"inform_ip": "192.168.1.1",
+					"mac":       "de:ad:be:ef:00:03",
+				},
+			},
+		},
+	))
+	defer done()
+
+	ssids, err := c.UnexpectedSSIDs(wantSite, []string{"home", "guest"})
+	if err != nil {
+		t.Fatalf("unexpected error from Client.UnexpectedSSIDs: %v", err)
+	}
+
+	want := map[string][]string{
+		"de:ad:be:ef:00:02": {"Home", "free wifi"},
+	}
+
+	if got := ssids; !reflect.DeepEqual(want, got) {
+		t.Fatalf("unexpected SSIDs:\n- want: %v\n-  got: %v", want, got)
+	}
+}